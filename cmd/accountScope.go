@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"os"
+	"reflect"
+
+	"github.com/spf13/cobra"
+	"github.com/staticbackendhq/backend-go"
+)
+
+// accountFlag holds the --account global flag, when set the users and db
+// commands operate on this account instead of yours.
+var accountFlag string
+
+var accountScopedToken string
+
+// checkAccountFlag stops commands that don't honour --account, they would
+// otherwise silently operate on your own account.
+func checkAccountFlag(cmd *cobra.Command, args []string) {
+	if len(accountFlag) == 0 || accountFlagSupported(cmd) {
+		return
+	}
+
+	printError("the --account option is not supported by \"%s\", only by the users and db commands and \"function run\"", cmd.CommandPath())
+	os.Exit(1)
+}
+
+func accountFlagSupported(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == usersCmd || c == dbCmd || c == functionRunCmd {
+			return true
+		}
+	}
+	return false
+}
+
+// getAccountToken returns a token scoped to the --account account, obtained
+// with the root token.
+func getAccountToken() (string, bool) {
+	if len(accountScopedToken) > 0 {
+		return accountScopedToken, true
+	}
+
+	rootTok, ok := getRootToken()
+	if !ok {
+		return "", false
+	}
+
+	tok, err := backend.SudoGetToken(rootTok, accountFlag)
+	if err != nil {
+		printError("unable to get a token for account %s: %v", accountFlag, err)
+		return "", false
+	}
+
+	accountScopedToken = tok
+	return tok, true
+}
+
+// getDBToken returns the token used by the db commands. Without --account it
+// is the root token and the sudo API is used, otherwise it's a token scoped to
+// the account and the regular API is used.
+func getDBToken() (tok string, scoped bool, ok bool) {
+	if len(accountFlag) > 0 {
+		tok, ok = getAccountToken()
+		return tok, true, ok
+	}
+
+	tok, ok = getRootToken()
+	return tok, false, ok
+}
+
+func dbListDocs(tok string, scoped bool, repo string, v any, lp *backend.ListParams) (backend.ListResult, error) {
+	if scoped {
+		return backend.List(tok, repo, v, lp)
+	}
+	return backend.SudoList(tok, repo, v, lp)
+}
+
+func dbFindDocs(tok string, scoped bool, repo string, filters []backend.QueryItem, v any, lp *backend.ListParams) (backend.ListResult, error) {
+	if scoped {
+		return backend.Find(tok, repo, filters, v, lp)
+	}
+	return backend.SudoFind(tok, repo, filters, v, lp)
+}
+
+func dbGetDoc(tok string, scoped bool, repo, id string, v any) error {
+	if scoped {
+		return backend.GetByID(tok, repo, id, v)
+	}
+	return backend.SudoGetByID(tok, repo, id, v)
+}
+
+func dbCreateDoc(tok string, scoped bool, repo string, doc, v any) error {
+	if scoped {
+		return backend.Create(tok, repo, doc, v)
+	}
+	return backend.SudoCreate(tok, repo, doc, v)
+}
+
+func dbUpdateDoc(tok string, scoped bool, repo, id string, doc, v any) error {
+	if scoped {
+		return backend.Update(tok, repo, id, doc, v)
+	}
+	return backend.SudoUpdate(tok, repo, id, doc, v)
+}
+
+// dbUpdateBody returns the fields to send to update orig to changed.
+// Updates merge the fields, so the removed ones are set to null.
+func dbUpdateBody(orig, changed map[string]any) map[string]any {
+	body := map[string]any{}
+
+	for k, v := range changed {
+		if ov, ok := orig[k]; !ok || !reflect.DeepEqual(ov, v) {
+			body[k] = v
+		}
+	}
+
+	for k := range orig {
+		if _, ok := changed[k]; !ok {
+			body[k] = nil
+		}
+	}
+
+	// the server manages the id and account of the documents
+	delete(body, "id")
+	delete(body, "accountId")
+
+	return body
+}
+
+// dbPresentFields returns the document without its null fields, the ones
+// removed by dbUpdateBody.
+func dbPresentFields(doc map[string]any) map[string]any {
+	present := map[string]any{}
+	for k, v := range doc {
+		if v != nil {
+			present[k] = v
+		}
+	}
+	return present
+}
+
+func dbDeleteDoc(tok string, scoped bool, repo, id string) error {
+	if scoped {
+		return backend.Delete(tok, repo, id)
+	}
+	return backend.SudoDelete(tok, repo, id)
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestAccountFlagSupported(t *testing.T) {
+	tests := []struct {
+		cmd  *cobra.Command
+		want bool
+	}{
+		{dbListCmd, true},
+		{usersListCmd, true},
+		{functionRunCmd, true},
+		{migrateUpCmd, false},
+		{taskAddCmd, false},
+		{formCmd, false},
+	}
+
+	for _, tt := range tests {
+		if got := accountFlagSupported(tt.cmd); got != tt.want {
+			t.Fatalf("accountFlagSupported(%s) = %v, want %v", tt.cmd.CommandPath(), got, tt.want)
+		}
+	}
+}
+
+func TestDBUpdateBody(t *testing.T) {
+	orig := map[string]any{"id": "1", "accountId": "a", "title": "x", "legacy": true, "n": float64(1)}
+	changed := map[string]any{"id": "1", "accountId": "a", "name": "x", "n": float64(2)}
+
+	want := map[string]any{"title": nil, "legacy": nil, "name": "x", "n": float64(2)}
+	body := dbUpdateBody(orig, changed)
+	if !reflect.DeepEqual(body, want) {
+		t.Fatalf("dbUpdateBody = %v, want %v", body, want)
+	}
+
+	for k, v := range body {
+		orig[k] = v
+	}
+	if got := dbPresentFields(orig); !reflect.DeepEqual(got, changed) {
+		t.Fatalf("dbPresentFields = %v, want %v", got, changed)
+	}
+}
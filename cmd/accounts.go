@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/staticbackendhq/backend-go"
+)
+
+// accountsCmd represents the accounts command
+var accountsCmd = &cobra.Command{
+	Use:   "accounts",
+	Short: "Inspect the accounts (tenants) of your application.",
+	Long: fmt.Sprintf(`
+%s
+
+StaticBackend scopes users and data by account. Use the global %s flag to
+run the users and db commands against a specific account:
+
+$> backend accounts members bob@acme.com
+$> backend db list tasks --account account-id
+	`,
+		clbold("Inspect accounts"),
+		clbold("--account"),
+	),
+}
+
+var accountsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the accounts you're associated with",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !setBackend() {
+			return
+		}
+
+		tok, ok := getAuthToken()
+		if !ok {
+			return
+		}
+
+		associations, err := backend.ListAssociations(tok)
+		if err != nil {
+			printError("An error occurred: %v", err)
+			return
+		}
+
+		fmt.Printf("%s account(s)\n\n", clbold(len(associations)))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
+		fmt.Fprintf(w, "ACCOUNT ID\tUSER ID\tEMAIL\tROLE\n")
+		for _, a := range associations {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", a.AccountID, a.UserID, a.Email, a.Role)
+		}
+		w.Flush()
+	},
+}
+
+var accountsMembersCmd = &cobra.Command{
+	Use:   "members <email>",
+	Short: "List the accounts a user is a member of",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !setBackend() {
+			return
+		}
+
+		if len(args) == 0 {
+			printError("Argument missing: email — please supply an email.")
+			return
+		}
+
+		tok, ok := getRootToken()
+		if !ok {
+			return
+		}
+
+		entries, err := backend.SudoGetUserAccounts(tok, args[0])
+		if err != nil {
+			printError("An error occurred: %v", err)
+			return
+		}
+
+		fmt.Printf("%s account(s)\n\n", clbold(len(entries)))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
+		fmt.Fprintf(w, "ACCOUNT ID\tROLE\tHOME\n")
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%d\t%t\n", e.AccountID, e.Role, e.Home)
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(accountsCmd)
+
+	accountsCmd.AddCommand(accountsListCmd)
+	accountsCmd.AddCommand(accountsMembersCmd)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/spf13/viper"
 	"github.com/staticbackendhq/backend-go"
+	"gopkg.in/yaml.v2"
 )
 
 func getPublicKey() (pubKey string, ok bool) {
@@ -43,6 +44,10 @@ func getRootToken() (tok string, ok bool) {
 }
 
 func getAuthToken() (tok string, ok bool) {
+	if len(accountFlag) > 0 {
+		return getAccountToken()
+	}
+
 	tok = cleanConfigValue(viper.GetString("authToken"))
 	if len(tok) == 0 {
 		printError("cannot find authToken in your .backend.yml config file")
@@ -75,21 +80,49 @@ func getAuthToken() (tok string, ok bool) {
 	return
 }
 
+// updateAuthToken saves the refreshed token in the config file, only the
+// authToken entry changes, the other entries and sections are kept.
 func updateAuthToken(newTok string) error {
-	pk := cleanConfigValue(viper.GetString("pubKey"))
-	region := normalizeBackendRegion(viper.GetString("region"))
-	rtoken := cleanConfigValue(viper.GetString("rootToken"))
-	email := cleanConfigValue(viper.GetString("email"))
-	password := cleanConfigValue(viper.GetString("password"))
-
-	s := fmt.Sprintf("pubKey: %s\nregion: %s\nrootToken: %s\nemail: %s\npassword: %s\nauthToken: %s", pk, region, rtoken, email, password, newTok)
-
 	path := viper.ConfigFileUsed()
 	if path == "" {
 		path = "./.backend.yml"
 	}
 
-	return os.WriteFile(path, []byte(s), 0660)
+	b, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	b, err = setConfigEntry(b, "authToken", newTok)
+	if err != nil {
+		return err
+	}
+
+	viper.Set("authToken", newTok)
+
+	return os.WriteFile(path, b, 0660)
+}
+
+// setConfigEntry sets a top-level entry of a YAML config file, keeping the
+// order of the other entries.
+func setConfigEntry(b []byte, key string, value any) ([]byte, error) {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("invalid config file: %w", err)
+	}
+
+	found := false
+	for i := range doc {
+		if k, ok := doc[i].Key.(string); ok && k == key {
+			doc[i].Value = value
+			found = true
+		}
+	}
+	if !found {
+		doc = append(doc, yaml.MapItem{Key: key, Value: value})
+	}
+
+	return yaml.Marshal(doc)
 }
 
 func setBackend() bool {
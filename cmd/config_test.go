@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetConfigEntry(t *testing.T) {
+	src := `pubKey: pk
+authToken: old
+twilioSid: sid
+server:
+  port: 8100
+  persistData: true
+`
+
+	b, err := setConfigEntry([]byte(src), "authToken", "new")
+	if err != nil {
+		t.Fatalf("setConfigEntry returned error: %v", err)
+	}
+
+	want := strings.Replace(src, "authToken: old", "authToken: new", 1)
+	if got := string(b); got != want {
+		t.Fatalf("setConfigEntry returned\n%s\nwant\n%s", got, want)
+	}
+
+	b, err = setConfigEntry(nil, "authToken", "new")
+	if err != nil || string(b) != "authToken: new\n" {
+		t.Fatalf("setConfigEntry on an empty file = %q, %v", b, err)
+	}
+}
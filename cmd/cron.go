@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed 5-field cron expression evaluated in UTC, or a
+// fixed delay for @every.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar track wildcards since a restricted day of month
+	// and day of week match when either matches.
+	domStar, dowStar bool
+	// every is the delay between runs of "@every <duration>".
+	every time.Duration
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}},
+	{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}},
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parseCron parses a standard cron expression: minute, hour, day of month,
+// month and day of week. Lists, ranges, steps, month and day names, the
+// @daily style descriptors and "@every <duration>" are supported.
+func parseCron(expr string) (cronSchedule, error) {
+	var s cronSchedule
+
+	expr = strings.TrimSpace(expr)
+	if d, ok := strings.CutPrefix(strings.ToLower(expr), "@every "); ok {
+		every, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil {
+			return s, fmt.Errorf("invalid @every duration: %w", err)
+		}
+		if every < time.Second {
+			return s, fmt.Errorf("the @every duration must be at least 1s, got %v", every)
+		}
+		s.every = every
+		return s, nil
+	}
+
+	if std, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = std
+	} else if strings.HasPrefix(expr, "@") {
+		return s, fmt.Errorf("unknown descriptor %q", expr)
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != len(cronFields) {
+		return s, fmt.Errorf("expected 5 fields (minute hour day-of-month month day-of-week), got %d", len(parts))
+	}
+
+	bits := make([]uint64, len(cronFields))
+	for i, f := range cronFields {
+		b, err := parseCronField(parts[i], f)
+		if err != nil {
+			return s, err
+		}
+		bits[i] = b
+	}
+
+	// 7 is an alias for sunday
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+
+	s.minute, s.hour, s.dom, s.month, s.dow = bits[0], bits[1], bits[2], bits[3], bits[4]
+	// like cron, a field starting with a wildcard, */2 included, does not
+	// restrict the days
+	s.domStar = strings.HasPrefix(parts[2], "*") || strings.HasPrefix(parts[2], "?")
+	s.dowStar = strings.HasPrefix(parts[4], "*") || strings.HasPrefix(parts[4], "?")
+
+	return s, nil
+}
+
+func parseCronField(raw string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(raw, ",") {
+		b, err := parseCronItem(item, f)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s %q: %w", f.name, raw, err)
+		}
+		bits |= b
+	}
+	return bits, nil
+}
+
+func parseCronItem(item string, f cronField) (uint64, error) {
+	if len(item) == 0 {
+		return 0, fmt.Errorf("empty value")
+	}
+
+	rangePart, stepPart, hasStep := strings.Cut(item, "/")
+
+	step := 1
+	if hasStep {
+		n, err := strconv.Atoi(stepPart)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("step %q must be a positive number", stepPart)
+		}
+		step = n
+	}
+
+	lo, hi := f.min, f.max
+	switch {
+	case rangePart == "*" || rangePart == "?":
+		if f.name == "day of week" {
+			hi = 6
+		}
+	case strings.Contains(rangePart, "-"):
+		a, b, _ := strings.Cut(rangePart, "-")
+		var err error
+		if lo, err = cronValue(a, f); err != nil {
+			return 0, err
+		}
+		if hi, err = cronValue(b, f); err != nil {
+			return 0, err
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("range start %d is after its end %d", lo, hi)
+		}
+	default:
+		v, err := cronValue(rangePart, f)
+		if err != nil {
+			return 0, err
+		}
+		lo = v
+		if !hasStep {
+			hi = v
+		}
+	}
+
+	var bits uint64
+	for v := lo; v <= hi; v += step {
+		bits |= 1 << uint(v)
+	}
+	return bits, nil
+}
+
+func cronValue(s string, f cronField) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%d is out of range %d-%d", v, f.min, f.max)
+	}
+
+	return v, nil
+}
+
+func (s cronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// next returns the first fire time strictly after t, or the zero time if
+// none is found within five years (ex: February 30th). The runs of @every
+// are counted from when the server schedules the task, they are estimated
+// from t.
+func (s cronSchedule) next(t time.Time) time.Time {
+	if s.every > 0 {
+		return t.UTC().Truncate(time.Second).Add(s.every)
+	}
+
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
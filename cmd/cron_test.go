@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCronNext(t *testing.T) {
+	from := time.Date(2024, 5, 10, 12, 30, 0, 0, time.UTC) // a friday
+
+	tests := map[string]time.Time{
+		"*/15 * * * *":      time.Date(2024, 5, 10, 12, 45, 0, 0, time.UTC),
+		"0 2 * * *":         time.Date(2024, 5, 11, 2, 0, 0, 0, time.UTC),
+		"30 12 * * *":       time.Date(2024, 5, 11, 12, 30, 0, 0, time.UTC),
+		"0 9 * * mon-fri":   time.Date(2024, 5, 13, 9, 0, 0, 0, time.UTC),
+		"0 0 1 jan *":       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		"0 0 * * 7":         time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC),
+		"@hourly":           time.Date(2024, 5, 10, 13, 0, 0, 0, time.UTC),
+		"0 0 13 * 1":        time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC),
+		"5,10 8-10/2 * * *": time.Date(2024, 5, 11, 8, 5, 0, 0, time.UTC),
+		"0 0 */2 * 1":       time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC),
+		"0 0 13 * */2":      time.Date(2024, 6, 13, 0, 0, 0, 0, time.UTC),
+		"@every 1h30m":      time.Date(2024, 5, 10, 14, 0, 0, 0, time.UTC),
+	}
+
+	for expr, want := range tests {
+		s, err := parseCron(expr)
+		if err != nil {
+			t.Fatalf("parseCron(%q) returned error: %v", expr, err)
+		}
+
+		if got := s.next(from); !got.Equal(want) {
+			t.Fatalf("parseCron(%q).next = %v, want %v", expr, got, want)
+		}
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	tests := map[string]string{
+		"* * * *":     "expected 5 fields",
+		"60 * * * *":  "invalid minute",
+		"* 24 * * *":  "invalid hour",
+		"* * 0 * *":   "invalid day of month",
+		"* * * foo *": "invalid month",
+		"* * * * 8":   "invalid day of week",
+		"*/0 * * * *": "positive number",
+		"5-1 * * * *": "range start",
+		"@often":      "unknown descriptor",
+		"@every soon": "invalid @every duration",
+		"@every 10ms": "at least 1s",
+	}
+
+	for expr, want := range tests {
+		_, err := parseCron(expr)
+		if err == nil {
+			t.Fatalf("parseCron(%q) returned nil error", expr)
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("parseCron(%q) returned %q, want it to contain %q", expr, err, want)
+		}
+	}
+}
+
+func TestCronNextImpossibleDate(t *testing.T) {
+	s, err := parseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("parseCron returned error: %v", err)
+	}
+
+	if got := s.next(time.Now()); !got.IsZero() {
+		t.Fatalf("next returned %v for February 30th", got)
+	}
+}
@@ -26,7 +26,7 @@ $> backend db count tasks done == true
 			return
 		}
 
-		tok, ok := getRootToken()
+		tok, _, ok := getDBToken()
 		if !ok {
 			return
 		}
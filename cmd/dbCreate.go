@@ -5,7 +5,6 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
-	"github.com/staticbackendhq/backend-go"
 )
 
 // dbListCmd list document in a repository
@@ -26,7 +25,7 @@ $> backend db create tasks "{ name: \"task 1\", assign: \"dominic\", done: false
 			return
 		}
 
-		tok, ok := getRootToken()
+		tok, scoped, ok := getDBToken()
 		if !ok {
 			return
 		}
@@ -49,7 +48,7 @@ $> backend db create tasks "{ name: \"task 1\", assign: \"dominic\", done: false
 		}
 
 		var result map[string]interface{}
-		if err := backend.SudoCreate(tok, repo, doc, &result); err != nil {
+		if err := dbCreateDoc(tok, scoped, repo, doc, &result); err != nil {
 			printError("An error occurred: %v", err)
 			return
 		}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
-	"github.com/staticbackendhq/backend-go"
 )
 
 // dbDeleteCmd deletes a document in a repository.
@@ -23,7 +22,7 @@ Permanently delete a specific document by its id from a repository.
 			return
 		}
 
-		tok, ok := getRootToken()
+		tok, scoped, ok := getDBToken()
 		if !ok {
 			return
 		}
@@ -37,7 +36,7 @@ Permanently delete a specific document by its id from a repository.
 		}
 
 		repo, id := args[0], args[1]
-		if err := backend.SudoDelete(tok, repo, id); err != nil {
+		if err := dbDeleteDoc(tok, scoped, repo, id); err != nil {
 			printError("An error occurred: %v", err)
 			return
 		}
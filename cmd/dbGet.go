@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
-	"github.com/staticbackendhq/backend-go"
 )
 
 // dbListCmd list document in a repository
@@ -23,7 +22,7 @@ Retrieve a specific document by its id from a repository.
 			return
 		}
 
-		tok, ok := getRootToken()
+		tok, scoped, ok := getDBToken()
 		if !ok {
 			return
 		}
@@ -39,7 +38,7 @@ Retrieve a specific document by its id from a repository.
 		repo, id := args[0], args[1]
 
 		var result map[string]interface{}
-		if err := backend.SudoGetByID(tok, repo, id, &result); err != nil {
+		if err := dbGetDoc(tok, scoped, repo, id, &result); err != nil {
 			printError("An error occurred: %v", err)
 			return
 		}
@@ -23,7 +23,7 @@ You may view documents from first to last or last to first.
 			return
 		}
 
-		tok, ok := getRootToken()
+		tok, scoped, ok := getDBToken()
 		if !ok {
 			return
 		}
@@ -62,7 +62,7 @@ You may view documents from first to last or last to first.
 		}
 
 		var results []map[string]interface{}
-		meta, err := backend.SudoList(tok, repo, &results, lp)
+		meta, err := dbListDocs(tok, scoped, repo, &results, lp)
 		if err != nil {
 			printError("An error occurred: %v", err)
 			return
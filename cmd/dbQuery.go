@@ -43,7 +43,7 @@ Supported operators:
 			return
 		}
 
-		tok, ok := getRootToken()
+		tok, scoped, ok := getDBToken()
 		if !ok {
 			return
 		}
@@ -91,7 +91,7 @@ Supported operators:
 		}
 
 		var results []map[string]interface{}
-		meta, err := backend.SudoFind(tok, repo, filters, &results, lp)
+		meta, err := dbFindDocs(tok, scoped, repo, filters, &results, lp)
 		if err != nil {
 			printError("An error occurred: %v", err)
 			return
@@ -0,0 +1,423 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/staticbackendhq/backend-go"
+)
+
+// dbSchemaCmd infers the schema of a repository from its documents.
+var dbSchemaCmd = &cobra.Command{
+	Use:   "schema repo-name",
+	Short: "Infer the schema of a repository from its documents.",
+	Long: fmt.Sprintf(`
+%s
+
+Repositories are schemaless, this command samples the most recent documents,
+or scans them all with --all, and reports each field's observed types, how
+often it is null or missing, example values and nested fields.
+
+Use --output to generate a JSON Schema, TypeScript or Go type definitions for
+your client code instead of the report:
+
+$> backend db schema tasks
+$> backend db schema tasks --all --output json-schema --file tasks.schema.json
+$> backend db schema tasks --output typescript --type-name Task
+$> backend db schema tasks --output go --type-name Task
+
+Fields present in every document are required, fields that are sometimes
+missing are optional, and fields that are sometimes null are nullable.
+	`,
+		clbold("Infer a repository schema"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !setBackend() {
+			return
+		}
+
+		if len(args) == 0 {
+			printError("Argument missing: repository — please supply a table name.")
+			return
+		}
+
+		repo := args[0]
+
+		output, _ := cmd.Flags().GetString("output")
+		switch output {
+		case "report", "json-schema", "typescript", "go":
+		default:
+			printError("invalid --output %q: must be report, json-schema, typescript or go", output)
+			return
+		}
+
+		sample, _ := cmd.Flags().GetInt("sample")
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			sample = 0
+		} else if sample <= 0 {
+			printError("--sample must be greater than 0, use --all to scan every document")
+			return
+		}
+
+		typeName, _ := cmd.Flags().GetString("type-name")
+		if len(typeName) == 0 {
+			typeName = schemaTypeName(repo)
+		}
+
+		tok, scoped, ok := getDBToken()
+		if !ok {
+			return
+		}
+
+		schema := newSchemaNode()
+		n, err := dbEachDoc(tok, scoped, repo, sample, func(doc map[string]any) error {
+			schema.observe(doc)
+			return nil
+		})
+		if err != nil {
+			printError("An error occurred: %v", err)
+			return
+		}
+
+		if n == 0 {
+			printWarning("%s has no documents, nothing to infer", repo)
+			return
+		}
+
+		var w io.Writer = os.Stdout
+		if dest, _ := cmd.Flags().GetString("file"); len(dest) > 0 {
+			f, err := os.Create(dest)
+			if err != nil {
+				printError("could not create %s: %v", dest, err)
+				return
+			}
+			defer f.Close()
+			w = f
+		}
+
+		switch output {
+		case "report":
+			fmt.Fprintf(w, "%d document(s) analyzed in %s\n\n", n, repo)
+			err = schemaReport(w, schema)
+		case "json-schema":
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(schemaJSONSchema(schema, typeName))
+		case "typescript":
+			_, err = io.WriteString(w, schemaTypeScript(schema, typeName))
+		case "go":
+			var src string
+			src, err = schemaGo(schema, typeName)
+			if err == nil {
+				_, err = io.WriteString(w, src)
+			}
+		}
+		if err != nil {
+			printError("An error occurred: %v", err)
+		}
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbSchemaCmd)
+
+	dbSchemaCmd.Flags().Int("sample", 1000, "Number of most recent documents to analyze")
+	dbSchemaCmd.Flags().Bool("all", false, "Analyze every document of the repository")
+	dbSchemaCmd.Flags().StringP("output", "o", "report", "output format: report, json-schema, typescript or go")
+	dbSchemaCmd.Flags().String("type-name", "", "name of the generated type (default from the repository name)")
+	dbSchemaCmd.Flags().String("file", "", "write the output to this file instead of stdout")
+}
+
+// dbEachDoc calls fn with the most recent documents of a repository, up to
+// limit, or all of them when limit is 0. It returns the number of documents.
+func dbEachDoc(tok string, scoped bool, repo string, limit int, fn func(map[string]any) error) (int, error) {
+	lp := &backend.ListParams{Page: 1, Size: 100, Descending: true}
+	if limit > 0 && limit < lp.Size {
+		lp.Size = limit
+	}
+
+	n := 0
+	for {
+		var docs []map[string]any
+		if _, err := dbListDocs(tok, scoped, repo, &docs, lp); err != nil {
+			return n, err
+		}
+
+		for _, doc := range docs {
+			if err := fn(doc); err != nil {
+				return n, err
+			}
+			n++
+			if limit > 0 && n >= limit {
+				return n, nil
+			}
+		}
+
+		if len(docs) < lp.Size {
+			return n, nil
+		}
+		lp.Page++
+	}
+}
+
+// JSON types of the observed values, integer is a number without decimals.
+const (
+	schemaString  = "string"
+	schemaInteger = "integer"
+	schemaNumber  = "number"
+	schemaBoolean = "boolean"
+	schemaObject  = "object"
+	schemaArray   = "array"
+	schemaNull    = "null"
+)
+
+// schemaMaxExamples is the number of distinct example values kept per field.
+const schemaMaxExamples = 3
+
+// schemaNode is what was observed for a value: the document itself, a field
+// or the items of an array.
+type schemaNode struct {
+	// seen is the number of values observed, including nulls.
+	seen  int
+	types map[string]int
+	// dateTimes counts the strings in RFC 3339 format.
+	dateTimes int
+	examples  []string
+
+	// fields are the properties of the observed objects, seen in
+	// types[schemaObject] objects.
+	fields map[string]*schemaNode
+	// items are the elements of the observed arrays.
+	items *schemaNode
+}
+
+func newSchemaNode() *schemaNode {
+	return &schemaNode{types: map[string]int{}}
+}
+
+func (sn *schemaNode) observe(v any) {
+	sn.seen++
+
+	switch v := v.(type) {
+	case nil:
+		sn.types[schemaNull]++
+	case string:
+		sn.types[schemaString]++
+		if _, err := time.Parse(time.RFC3339, v); err == nil {
+			sn.dateTimes++
+		}
+		sn.example(v)
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			sn.types[schemaInteger]++
+		} else {
+			sn.types[schemaNumber]++
+		}
+		sn.example(v)
+	case bool:
+		sn.types[schemaBoolean]++
+		sn.example(v)
+	case map[string]any:
+		sn.types[schemaObject]++
+		if sn.fields == nil {
+			sn.fields = map[string]*schemaNode{}
+		}
+		for k, fv := range v {
+			field, ok := sn.fields[k]
+			if !ok {
+				field = newSchemaNode()
+				sn.fields[k] = field
+			}
+			field.observe(fv)
+		}
+	case []any:
+		sn.types[schemaArray]++
+		if sn.items == nil {
+			sn.items = newSchemaNode()
+		}
+		for _, item := range v {
+			sn.items.observe(item)
+		}
+	default:
+		// values decoded from JSON have one of the types above
+		sn.types[fmt.Sprintf("%T", v)]++
+	}
+}
+
+func (sn *schemaNode) example(v any) {
+	if len(sn.examples) >= schemaMaxExamples {
+		return
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+
+	s := string(b)
+	if len(s) > 40 {
+		s = s[:37] + "..."
+	}
+
+	for _, e := range sn.examples {
+		if e == s {
+			return
+		}
+	}
+	sn.examples = append(sn.examples, s)
+}
+
+// typeNames returns the observed types except null, most frequent first,
+// integer is merged into number when both were observed.
+func (sn *schemaNode) typeNames() []string {
+	counts := map[string]int{}
+	for t, n := range sn.types {
+		if t != schemaNull {
+			counts[t] = n
+		}
+	}
+
+	if n, ok := counts[schemaInteger]; ok && counts[schemaNumber] > 0 {
+		counts[schemaNumber] += n
+		delete(counts, schemaInteger)
+	}
+
+	names := make([]string, 0, len(counts))
+	for t := range counts {
+		names = append(names, t)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+func (sn *schemaNode) nullable() bool {
+	return sn.types[schemaNull] > 0
+}
+
+// isDateTime reports whether every observed string is a date-time.
+func (sn *schemaNode) isDateTime() bool {
+	return sn.types[schemaString] > 0 && sn.dateTimes == sn.types[schemaString]
+}
+
+// objects is the number of objects observed, the fields may be missing
+// from some of them.
+func (sn *schemaNode) objects() int {
+	return sn.types[schemaObject]
+}
+
+func (sn *schemaNode) fieldNames() []string {
+	names := make([]string, 0, len(sn.fields))
+	for k := range sn.fields {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// required reports whether the field was present in every object.
+func (sn *schemaNode) required(field string) bool {
+	f, ok := sn.fields[field]
+	return ok && f.seen == sn.objects()
+}
+
+// schemaReport prints each field, nested ones with a dotted path and array
+// items with [].
+func schemaReport(w io.Writer, root *schemaNode) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIELD\tTYPES\tNULL\tMISSING\tEXAMPLES")
+	schemaReportFields(tw, "", root)
+	return tw.Flush()
+}
+
+func schemaReportFields(w io.Writer, prefix string, parent *schemaNode) {
+	total := parent.objects()
+
+	for _, name := range parent.fieldNames() {
+		f := parent.fields[name]
+		path := prefix + name
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			path,
+			schemaReportTypes(f),
+			schemaPercent(f.types[schemaNull], f.seen),
+			schemaPercent(total-f.seen, total),
+			strings.Join(f.examples, ", "),
+		)
+
+		schemaReportChildren(w, path, f)
+	}
+}
+
+func schemaReportChildren(w io.Writer, path string, f *schemaNode) {
+	if f.objects() > 0 {
+		schemaReportFields(w, path+".", f)
+	}
+
+	if f.items != nil && f.items.seen > 0 {
+		items := f.items
+		fmt.Fprintf(w, "%s[]\t%s\t%s\t\t%s\n",
+			path,
+			schemaReportTypes(items),
+			schemaPercent(items.types[schemaNull], items.seen),
+			strings.Join(items.examples, ", "),
+		)
+		schemaReportChildren(w, path+"[]", items)
+	}
+}
+
+// schemaReportTypes lists the types with their share when they are mixed.
+func schemaReportTypes(sn *schemaNode) string {
+	names := sn.typeNames()
+	if len(names) == 0 {
+		return schemaNull
+	}
+
+	label := func(t string) string {
+		if t == schemaString && sn.isDateTime() {
+			return "string (date-time)"
+		}
+		if t == schemaArray && (sn.items == nil || sn.items.seen == 0) {
+			return "array (empty)"
+		}
+		return t
+	}
+
+	if len(names) == 1 {
+		return label(names[0])
+	}
+
+	nonNull := sn.seen - sn.types[schemaNull]
+	parts := make([]string, len(names))
+	for i, t := range names {
+		n := sn.types[t]
+		if t == schemaNumber {
+			n += sn.types[schemaInteger]
+		}
+		parts[i] = fmt.Sprintf("%s %s", label(t), schemaPercent(n, nonNull))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func schemaPercent(n, total int) string {
+	if total == 0 || n == 0 {
+		return "0%"
+	}
+	p := float64(n) * 100 / float64(total)
+	if p < 1 {
+		return "<1%"
+	}
+	return fmt.Sprintf("%.0f%%", p)
+}
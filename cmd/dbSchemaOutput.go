@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"fmt"
+	"go/format"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// schemaTypeName converts a repository name to a type name: user_tasks
+// gives UserTasks.
+func schemaTypeName(repo string) string {
+	name := schemaGoName(repo)
+	if name == "" {
+		return "Document"
+	}
+	return name
+}
+
+// schemaJSONSchema returns a JSON Schema (draft 2020-12) of the documents.
+func schemaJSONSchema(root *schemaNode, title string) map[string]any {
+	s := schemaJSONNode(root)
+	s["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	s["title"] = title
+	return s
+}
+
+func schemaJSONNode(sn *schemaNode) map[string]any {
+	s := map[string]any{}
+
+	types := sn.typeNames()
+	if sn.nullable() {
+		types = append(types, schemaNull)
+	}
+
+	switch len(types) {
+	case 0:
+		// only empty arrays were observed, any value is valid
+	case 1:
+		s["type"] = types[0]
+	default:
+		s["type"] = types
+	}
+
+	if sn.isDateTime() && sn.types[schemaString] == sn.seen-sn.types[schemaNull] {
+		s["format"] = "date-time"
+	}
+
+	if sn.objects() > 0 {
+		props := map[string]any{}
+		var required []string
+		for _, name := range sn.fieldNames() {
+			props[name] = schemaJSONNode(sn.fields[name])
+			if sn.required(name) {
+				required = append(required, name)
+			}
+		}
+		s["properties"] = props
+		if len(required) > 0 {
+			s["required"] = required
+		}
+	}
+
+	if sn.items != nil && sn.items.seen > 0 {
+		s["items"] = schemaJSONNode(sn.items)
+	}
+
+	if len(sn.examples) > 0 {
+		s["examples"] = schemaExampleValues(sn.examples)
+	}
+
+	return s
+}
+
+// schemaExampleValues returns the examples as JSON values.
+func schemaExampleValues(examples []string) []any {
+	values := make([]any, 0, len(examples))
+	for _, e := range examples {
+		if strings.HasSuffix(e, "...") {
+			// truncated examples are not valid JSON anymore
+			continue
+		}
+		values = append(values, schemaRawJSON(e))
+	}
+	return values
+}
+
+// schemaRawJSON is an example value already encoded as JSON.
+type schemaRawJSON string
+
+func (r schemaRawJSON) MarshalJSON() ([]byte, error) {
+	return []byte(r), nil
+}
+
+var tsIdentifierRe = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// schemaTypeScript returns a TypeScript interface of the documents.
+func schemaTypeScript(root *schemaNode, name string) string {
+	return fmt.Sprintf("export interface %s %s\n", name, schemaTSObject(root, ""))
+}
+
+func schemaTSObject(sn *schemaNode, indent string) string {
+	var sb strings.Builder
+	sb.WriteString("{\n")
+
+	for _, name := range sn.fieldNames() {
+		key := name
+		if !tsIdentifierRe.MatchString(name) {
+			key = fmt.Sprintf("%q", name)
+		}
+		if !sn.required(name) {
+			key += "?"
+		}
+
+		fmt.Fprintf(&sb, "%s  %s: %s;\n", indent, key, schemaTSType(sn.fields[name], indent+"  "))
+	}
+
+	sb.WriteString(indent + "}")
+	return sb.String()
+}
+
+func schemaTSType(sn *schemaNode, indent string) string {
+	var parts []string
+	for _, t := range sn.typeNames() {
+		switch t {
+		case schemaString:
+			parts = append(parts, "string")
+		case schemaInteger, schemaNumber:
+			parts = append(parts, "number")
+		case schemaBoolean:
+			parts = append(parts, "boolean")
+		case schemaObject:
+			parts = append(parts, schemaTSObject(sn, indent))
+		case schemaArray:
+			item := "unknown"
+			if sn.items != nil && sn.items.seen > 0 {
+				item = schemaTSType(sn.items, indent)
+			}
+			if strings.Contains(item, " | ") {
+				item = "(" + item + ")"
+			}
+			parts = append(parts, item+"[]")
+		default:
+			parts = append(parts, "unknown")
+		}
+	}
+
+	if len(parts) == 0 {
+		parts = append(parts, "unknown")
+	}
+	if sn.nullable() {
+		parts = append(parts, "null")
+	}
+	return strings.Join(parts, " | ")
+}
+
+// schemaGo returns a gofmt'ed Go struct of the documents.
+func schemaGo(root *schemaNode, name string) (string, error) {
+	src := fmt.Sprintf("type %s %s\n", name, schemaGoStruct(root))
+
+	b, err := format.Source([]byte(src))
+	if err != nil {
+		return "", fmt.Errorf("unable to format the Go type: %w", err)
+	}
+
+	return string(b), nil
+}
+
+func schemaGoStruct(sn *schemaNode) string {
+	var sb strings.Builder
+	sb.WriteString("struct {\n")
+
+	used := map[string]int{}
+	for _, name := range sn.fieldNames() {
+		field := schemaGoName(name)
+		if field == "" {
+			field = "Field"
+		}
+		if n := used[field]; n > 0 {
+			used[field]++
+			field = fmt.Sprintf("%s%d", field, n+1)
+		} else {
+			used[field] = 1
+		}
+
+		tag := name
+		if !sn.required(name) {
+			tag += ",omitempty"
+		}
+
+		fmt.Fprintf(&sb, "%s %s `json:%q`\n", field, schemaGoType(sn.fields[name], !sn.required(name)), tag)
+	}
+
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// schemaGoType returns the Go type of a value, a pointer when it is nullable
+// or optional and its zero value would be ambiguous.
+func schemaGoType(sn *schemaNode, optional bool) string {
+	types := sn.typeNames()
+	if len(types) != 1 {
+		return "any"
+	}
+
+	var typ string
+	switch types[0] {
+	case schemaString:
+		typ = "string"
+		if sn.isDateTime() {
+			typ = "time.Time"
+		}
+	case schemaInteger:
+		typ = "int64"
+	case schemaNumber:
+		typ = "float64"
+	case schemaBoolean:
+		typ = "bool"
+	case schemaObject:
+		typ = schemaGoStruct(sn)
+	case schemaArray:
+		item := "any"
+		if sn.items != nil && sn.items.seen > 0 {
+			item = schemaGoType(sn.items, false)
+		}
+		// a nil slice already encodes null
+		return "[]" + item
+	default:
+		return "any"
+	}
+
+	if sn.nullable() || (optional && typ != "string") {
+		return "*" + typ
+	}
+	return typ
+}
+
+// schemaGoInitialisms are written in upper case in Go names.
+var schemaGoInitialisms = map[string]bool{
+	"api": true, "html": true, "http": true, "id": true, "ip": true, "json": true,
+	"sms": true, "sql": true, "uri": true, "url": true, "uuid": true,
+}
+
+// schemaGoName converts a field name to an exported Go name: accountId gives
+// AccountID and created_at gives CreatedAt.
+func schemaGoName(s string) string {
+	var words []string
+	var word []rune
+
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+
+	var sb strings.Builder
+	for _, w := range words {
+		lower := strings.ToLower(w)
+		if schemaGoInitialisms[lower] {
+			sb.WriteString(strings.ToUpper(lower))
+			continue
+		}
+		r := []rune(w)
+		sb.WriteString(string(unicode.ToUpper(r[0])) + string(r[1:]))
+	}
+
+	name := sb.String()
+	if len(name) > 0 && unicode.IsDigit([]rune(name)[0]) {
+		name = "F" + name
+	}
+	return name
+}
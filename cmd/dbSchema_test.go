@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func testSchema(t *testing.T, docs ...string) *schemaNode {
+	t.Helper()
+
+	root := newSchemaNode()
+	for _, s := range docs {
+		var doc map[string]any
+		if err := json.Unmarshal([]byte(s), &doc); err != nil {
+			t.Fatalf("invalid test document %s: %v", s, err)
+		}
+		root.observe(doc)
+	}
+	return root
+}
+
+func TestSchemaObserve(t *testing.T) {
+	root := testSchema(t,
+		`{"id": "1", "title": "a", "done": true, "due": "2026-01-02T15:04:05Z", "score": 1, "tags": ["x"], "owner": {"name": "ann"}}`,
+		`{"id": "2", "title": null, "done": false, "due": "2026-02-02T15:04:05Z", "score": 1.5, "tags": [], "owner": {"name": "bob", "age": 42}}`,
+		`{"id": "3", "title": "c", "score": "n/a", "tags": [1]}`,
+	)
+
+	if !root.required("id") || root.required("done") {
+		t.Fatalf("id should be required and done optional")
+	}
+
+	if f := root.fields["title"]; !f.nullable() || strings.Join(f.typeNames(), ",") != "string" {
+		t.Fatalf("title types = %v nullable = %v, want a nullable string", f.typeNames(), f.nullable())
+	}
+
+	if got := strings.Join(root.fields["score"].typeNames(), ","); got != "number,string" {
+		t.Fatalf("score types = %s, want number,string", got)
+	}
+
+	if !root.fields["due"].isDateTime() {
+		t.Fatal("due should be a date-time")
+	}
+
+	if got := strings.Join(root.fields["tags"].items.typeNames(), ","); got != "integer,string" {
+		t.Fatalf("tags items types = %s, want integer,string", got)
+	}
+
+	owner := root.fields["owner"]
+	if owner.objects() != 2 || !owner.required("name") || owner.required("age") {
+		t.Fatalf("owner.name should be required and owner.age optional")
+	}
+
+	if got := schemaPercent(3-owner.seen, 3); got != "33%" {
+		t.Fatalf("owner missing = %s, want 33%%", got)
+	}
+
+	if got := strings.Join(root.fields["title"].examples, ","); got != `"a","c"` {
+		t.Fatalf("title examples = %s", got)
+	}
+}
+
+func TestSchemaOutputs(t *testing.T) {
+	root := testSchema(t,
+		`{"id": "1", "accountId": "a", "count": 2, "due": "2026-01-02T15:04:05Z", "tags": ["x"], "meta": {"x-ray": true}}`,
+		`{"id": "2", "accountId": "a", "count": null, "due": "2026-01-03T15:04:05Z", "tags": ["y"]}`,
+	)
+
+	b, err := json.Marshal(schemaJSONSchema(root, "Task"))
+	if err != nil {
+		t.Fatalf("unable to encode the JSON Schema: %v", err)
+	}
+	js := string(b)
+	for _, want := range []string{
+		`"required":["accountId","count","due","id","tags"]`,
+		`"count":{"examples":[2],"type":["integer","null"]}`,
+		`"format":"date-time"`,
+		`"items":{"examples":["x","y"],"type":"string"}`,
+		`"title":"Task"`,
+	} {
+		if !strings.Contains(js, want) {
+			t.Fatalf("JSON Schema %s does not contain %s", js, want)
+		}
+	}
+
+	ts := schemaTypeScript(root, "Task")
+	for _, want := range []string{
+		"export interface Task {",
+		"  count: number | null;",
+		"  meta?: {\n    \"x-ray\": boolean;\n  };",
+		"  tags: string[];",
+	} {
+		if !strings.Contains(ts, want) {
+			t.Fatalf("TypeScript\n%s\ndoes not contain %q", ts, want)
+		}
+	}
+
+	src, err := schemaGo(root, "Task")
+	if err != nil {
+		t.Fatalf("schemaGo returned error: %v", err)
+	}
+	// gofmt aligns the fields, compare with single spaces
+	flat := strings.Join(strings.Fields(src), " ")
+	for _, want := range []string{
+		"type Task struct {",
+		"AccountID string `json:\"accountId\"`",
+		"Count *int64 `json:\"count\"`",
+		"Due time.Time `json:\"due\"`",
+		"Meta *struct { XRay bool `json:\"x-ray\"` } `json:\"meta,omitempty\"`",
+		"Tags []string `json:\"tags\"`",
+	} {
+		if !strings.Contains(flat, want) {
+			t.Fatalf("Go\n%s\ndoes not contain %q", src, want)
+		}
+	}
+}
+
+func TestSchemaGoName(t *testing.T) {
+	tests := map[string]string{
+		"accountId":  "AccountID",
+		"created_at": "CreatedAt",
+		"user-tasks": "UserTasks",
+		"imageURL":   "ImageURL",
+		"2fa":        "F2fa",
+		"_":          "",
+	}
+
+	for in, want := range tests {
+		if got := schemaGoName(in); got != want {
+			t.Fatalf("schemaGoName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
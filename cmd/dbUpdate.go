@@ -5,7 +5,6 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
-	"github.com/staticbackendhq/backend-go"
 )
 
 // dbUpdateCmd updates a document in a repository.
@@ -26,7 +25,7 @@ $> backend db update tasks doc-id "{ \"done\": true }"
 			return
 		}
 
-		tok, ok := getRootToken()
+		tok, scoped, ok := getDBToken()
 		if !ok {
 			return
 		}
@@ -51,7 +50,7 @@ $> backend db update tasks doc-id "{ \"done\": true }"
 		}
 
 		var result map[string]interface{}
-		if err := backend.SudoUpdate(tok, repo, id, doc, &result); err != nil {
+		if err := dbUpdateDoc(tok, scoped, repo, id, doc, &result); err != nil {
 			printError("An error occurred: %v", err)
 			return
 		}
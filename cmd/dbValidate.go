@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of db validate, for CI pipelines.
+const (
+	dbValidateInvalid = 1
+	dbValidateFailed  = 2
+)
+
+// dbValidateCmd checks the documents of a repository against a JSON Schema.
+var dbValidateCmd = &cobra.Command{
+	Use:   "validate repo-name --schema schema.json",
+	Short: "Validate the documents of a repository against a JSON Schema.",
+	Long: fmt.Sprintf(`
+%s
+
+Every document of the repository is checked against the JSON Schema, the
+invalid ones are listed with their violations: type mismatches, missing
+required fields, unexpected fields, values out of range, and so on.
+
+$> backend db schema tasks --output json-schema --file tasks.schema.json
+$> backend db validate tasks --schema tasks.schema.json
+
+The command exits with 1 when some documents are invalid and 2 when the
+validation could not run, so it can be used in CI.
+
+Use --fix to repair the invalid documents with a script. It runs through the
+shell for each invalid document, receiving the document as JSON on stdin and
+the violations as a JSON array in the BACKEND_VIOLATIONS environment
+variable. The script prints the fixed document on stdout, or nothing to skip
+it. Fixed documents that pass the schema are updated, use --dry-run to only
+report them. Updates merge the fields, so the fields a script removes are set
+to null, and the document is only counted as fixed when the stored result
+matches the schema:
+
+$> backend db validate tasks --schema tasks.schema.json --fix "node fix-tasks.js"
+	`,
+		clbold("Validate documents"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !setBackend() {
+			os.Exit(dbValidateFailed)
+		}
+
+		if len(args) == 0 {
+			printError("Argument missing: repository — please supply a table name.")
+			os.Exit(dbValidateFailed)
+		}
+
+		repo := args[0]
+
+		schemaFile, _ := cmd.Flags().GetString("schema")
+		if len(schemaFile) == 0 {
+			printError("Flag missing: --schema — please supply a JSON Schema file.")
+			os.Exit(dbValidateFailed)
+		}
+
+		sv, err := loadSchemaValidator(schemaFile)
+		if err != nil {
+			printError("invalid schema %s: %v", schemaFile, err)
+			os.Exit(dbValidateFailed)
+		}
+
+		fix, _ := cmd.Flags().GetString("fix")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if dryRun && len(fix) == 0 {
+			printError("--dry-run requires --fix")
+			os.Exit(dbValidateFailed)
+		}
+
+		tok, scoped, ok := getDBToken()
+		if !ok {
+			os.Exit(dbValidateFailed)
+		}
+
+		var invalid, fixed int
+		n, err := dbEachDoc(tok, scoped, repo, 0, func(doc map[string]any) error {
+			violations := sv.validate(doc)
+			if len(violations) == 0 {
+				return nil
+			}
+
+			id, _ := doc["id"].(string)
+			fmt.Println(clbold(id))
+			for _, v := range violations {
+				fmt.Printf("  %s\n", v)
+			}
+
+			if len(fix) == 0 {
+				invalid++
+				return nil
+			}
+
+			if dbValidateFix(tok, scoped, repo, id, fix, doc, violations, sv, dryRun) {
+				fixed++
+			} else {
+				invalid++
+			}
+			return nil
+		})
+		if err != nil {
+			printError("An error occurred: %v", err)
+			os.Exit(dbValidateFailed)
+		}
+
+		fmt.Println()
+		if fixed > 0 {
+			verb := "fixed"
+			if dryRun {
+				verb = "would be fixed"
+			}
+			printSuccess("%d document(s) %s", fixed, verb)
+		}
+
+		if invalid > 0 {
+			printError("%d of %d document(s) do not match %s", invalid, n, schemaFile)
+			os.Exit(dbValidateInvalid)
+		}
+
+		printSuccess("%d document(s) match %s", n-fixed, schemaFile)
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbValidateCmd)
+
+	dbValidateCmd.Flags().String("schema", "", "JSON Schema file the documents must match")
+	dbValidateCmd.Flags().String("fix", "", "command fixing an invalid document read on stdin, printing it on stdout")
+	dbValidateCmd.Flags().Bool("dry-run", false, "report the documents --fix would update without updating them")
+}
+
+// dbValidateFix runs the fix command for an invalid document and updates it
+// when the fixed document is valid. It reports whether the document was
+// fixed.
+func dbValidateFix(tok string, scoped bool, repo, id, fix string, doc map[string]any, violations []schemaViolation, sv *schemaValidator, dryRun bool) bool {
+	fixedDoc, err := runSchemaFix(fix, doc, violations)
+	if err != nil {
+		printWarning("%s: --fix failed: %v", id, err)
+		return false
+	}
+	if fixedDoc == nil {
+		fmt.Println("  skipped by --fix")
+		return false
+	}
+
+	if remaining := sv.validate(fixedDoc); len(remaining) > 0 {
+		fmt.Println("  still invalid after --fix:")
+		for _, v := range remaining {
+			fmt.Printf("    %s\n", v)
+		}
+		return false
+	}
+
+	if dryRun {
+		fmt.Println("  would be fixed")
+		return true
+	}
+
+	var updated map[string]any
+	if err := dbUpdateDoc(tok, scoped, repo, id, dbUpdateBody(doc, fixedDoc), &updated); err != nil {
+		printWarning("%s: unable to update the fixed document: %v", id, err)
+		return false
+	}
+
+	// updates merge the fields, the removed ones are null in the stored
+	// document
+	if remaining := sv.validate(updated); len(remaining) > 0 {
+		fmt.Println("  updated but still invalid:")
+		for _, v := range remaining {
+			fmt.Printf("    %s\n", v)
+		}
+		return false
+	}
+
+	fmt.Println("  fixed")
+	return true
+}
+
+// runSchemaFix runs the fix command through the shell with the document on
+// stdin, and returns the fixed document it prints, nil when it prints
+// nothing.
+func runSchemaFix(command string, doc map[string]any, violations []schemaViolation) (map[string]any, error) {
+	in, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]string, len(violations))
+	for i, v := range violations {
+		list[i] = v.String()
+	}
+	env, err := json.Marshal(list)
+	if err != nil {
+		return nil, err
+	}
+
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.Command("cmd", "/C", command)
+	} else {
+		c = exec.Command("sh", "-c", command)
+	}
+
+	var stdout, stderr bytes.Buffer
+	c.Env = append(os.Environ(), "BACKEND_VIOLATIONS="+string(env))
+	c.Stdin = bytes.NewReader(in)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); len(msg) > 0 {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+
+	out := bytes.TrimSpace(stdout.Bytes())
+	if len(out) == 0 {
+		return nil, nil
+	}
+
+	var fixed map[string]any
+	if err := json.Unmarshal(out, &fixed); err != nil {
+		return nil, fmt.Errorf("the output is not a JSON document: %w", err)
+	}
+	return fixed, nil
+}
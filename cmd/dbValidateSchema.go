@@ -0,0 +1,410 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// schemaViolation is a value not matching the schema, path is empty for the
+// document itself.
+type schemaViolation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (v schemaViolation) String() string {
+	if len(v.Path) == 0 {
+		return v.Message
+	}
+	return v.Path + ": " + v.Message
+}
+
+// schemaValidator checks documents against a JSON Schema. It supports the
+// keywords describing document shapes: type, enum, const, properties,
+// required, additionalProperties, items, the length and range limits,
+// pattern, the date-time, date and email formats, allOf, anyOf, oneOf, not
+// and local $ref.
+type schemaValidator struct {
+	root     any
+	patterns map[string]*regexp.Regexp
+}
+
+func loadSchemaValidator(path string) (*schemaValidator, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return newSchemaValidator(b)
+}
+
+func newSchemaValidator(b []byte) (*schemaValidator, error) {
+	var root any
+	if err := json.Unmarshal(b, &root); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	switch root.(type) {
+	case map[string]any, bool:
+	default:
+		return nil, fmt.Errorf("a schema must be an object or a boolean")
+	}
+
+	sv := &schemaValidator{root: root, patterns: map[string]*regexp.Regexp{}}
+	if err := sv.prepare(root); err != nil {
+		return nil, err
+	}
+	return sv, nil
+}
+
+// prepare compiles the patterns and resolves the $ref of the schema, so the
+// errors are reported before checking any document.
+func (sv *schemaValidator) prepare(s any) error {
+	switch s := s.(type) {
+	case map[string]any:
+		for k, v := range s {
+			switch k {
+			case "$ref":
+				if ref, ok := v.(string); ok {
+					if _, err := sv.resolve(ref); err != nil {
+						return err
+					}
+				}
+			case "pattern":
+				if p, ok := v.(string); ok {
+					re, err := regexp.Compile(p)
+					if err != nil {
+						return fmt.Errorf("invalid pattern %q: %w", p, err)
+					}
+					sv.patterns[p] = re
+				}
+			case "enum", "const", "examples", "default":
+				// values, not schemas
+				continue
+			}
+
+			if err := sv.prepare(v); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, v := range s {
+			if err := sv.prepare(v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolve returns the schema a local $ref points to, like #/$defs/address.
+func (sv *schemaValidator) resolve(ref string) (any, error) {
+	if ref != "#" && !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported $ref %q: only references within the schema file are supported", ref)
+	}
+
+	s := sv.root
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#"), "/")[1:] {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		switch node := s.(type) {
+		case map[string]any:
+			v, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("unresolved $ref %q", ref)
+			}
+			s = v
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("unresolved $ref %q", ref)
+			}
+			s = node[i]
+		default:
+			return nil, fmt.Errorf("unresolved $ref %q", ref)
+		}
+	}
+	return s, nil
+}
+
+// validate returns the violations of a document.
+func (sv *schemaValidator) validate(doc any) []schemaViolation {
+	var out []schemaViolation
+	sv.check(sv.root, doc, "", &out)
+	return out
+}
+
+func (sv *schemaValidator) check(s, v any, path string, out *[]schemaViolation) {
+	fail := func(format string, args ...any) {
+		*out = append(*out, schemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	schema, ok := s.(map[string]any)
+	if !ok {
+		if b, ok := s.(bool); ok && !b {
+			fail("no value is allowed")
+		}
+		return
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		if target, err := sv.resolve(ref); err == nil {
+			sv.check(target, v, path, out)
+		}
+	}
+
+	if t, ok := schema["type"]; ok && !schemaTypeMatches(t, v) {
+		fail("expected %s, got %s", schemaTypeList(t), schemaValueType(v))
+		// the other keywords would only repeat the mismatch
+		return
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if reflect.DeepEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("%s is not one of %s", schemaShortJSON(v), schemaShortJSON(enum))
+		}
+	}
+
+	if c, ok := schema["const"]; ok && !reflect.DeepEqual(c, v) {
+		fail("expected %s, got %s", schemaShortJSON(c), schemaShortJSON(v))
+	}
+
+	switch v := v.(type) {
+	case string:
+		sv.checkString(schema, v, fail)
+	case float64:
+		checkSchemaNumber(schema, v, fail)
+	case []any:
+		if n, ok := schemaNumberKeyword(schema, "minItems"); ok && float64(len(v)) < n {
+			fail("expected at least %v item(s), got %d", n, len(v))
+		}
+		if n, ok := schemaNumberKeyword(schema, "maxItems"); ok && float64(len(v)) > n {
+			fail("expected at most %v item(s), got %d", n, len(v))
+		}
+		if items, ok := schema["items"]; ok {
+			for i, item := range v {
+				sv.check(items, item, fmt.Sprintf("%s[%d]", path, i), out)
+			}
+		}
+	case map[string]any:
+		sv.checkObject(schema, v, path, out)
+	}
+
+	if all, ok := schema["allOf"].([]any); ok {
+		for _, sub := range all {
+			sv.check(sub, v, path, out)
+		}
+	}
+
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		if sv.matches(anyOf, v) == 0 {
+			fail("does not match any of the anyOf schemas")
+		}
+	}
+
+	if oneOf, ok := schema["oneOf"].([]any); ok {
+		if n := sv.matches(oneOf, v); n != 1 {
+			fail("matches %d of the oneOf schemas instead of exactly one", n)
+		}
+	}
+
+	if not, ok := schema["not"]; ok {
+		if len(sv.violations(not, v)) == 0 {
+			fail("matches the not schema")
+		}
+	}
+}
+
+func (sv *schemaValidator) checkObject(schema, obj map[string]any, path string, out *[]schemaViolation) {
+	if required, ok := schema["required"].([]any); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, ok := obj[name]; !ok {
+				*out = append(*out, schemaViolation{Path: schemaFieldPath(path, name), Message: "missing required field"})
+			}
+		}
+	}
+
+	props, _ := schema["properties"].(map[string]any)
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fieldPath := schemaFieldPath(path, name)
+		if ps, ok := props[name]; ok {
+			sv.check(ps, obj[name], fieldPath, out)
+			continue
+		}
+
+		switch ap := schema["additionalProperties"].(type) {
+		case bool:
+			if !ap {
+				*out = append(*out, schemaViolation{Path: fieldPath, Message: "unexpected field"})
+			}
+		case map[string]any:
+			sv.check(ap, obj[name], fieldPath, out)
+		}
+	}
+}
+
+func (sv *schemaValidator) checkString(schema map[string]any, s string, fail func(string, ...any)) {
+	n := float64(utf8.RuneCountInString(s))
+	if min, ok := schemaNumberKeyword(schema, "minLength"); ok && n < min {
+		fail("expected at least %v character(s), got %v", min, n)
+	}
+	if max, ok := schemaNumberKeyword(schema, "maxLength"); ok && n > max {
+		fail("expected at most %v character(s), got %v", max, n)
+	}
+
+	if p, ok := schema["pattern"].(string); ok {
+		re, ok := sv.patterns[p]
+		if !ok {
+			// a pattern under a keyword prepare does not walk, like a
+			// property named enum
+			re, _ = regexp.Compile(p)
+			sv.patterns[p] = re
+		}
+		if re != nil && !re.MatchString(s) {
+			fail("%s does not match the pattern %s", schemaShortJSON(s), p)
+		}
+	}
+
+	var err error
+	switch schema["format"] {
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, s)
+	case "date":
+		_, err = time.Parse("2006-01-02", s)
+	case "email":
+		_, err = mail.ParseAddress(s)
+	default:
+		return
+	}
+	if err != nil {
+		fail("%s is not a valid %s", schemaShortJSON(s), schema["format"])
+	}
+}
+
+func checkSchemaNumber(schema map[string]any, n float64, fail func(string, ...any)) {
+	if min, ok := schemaNumberKeyword(schema, "minimum"); ok && n < min {
+		fail("expected a value >= %v, got %v", min, n)
+	}
+	if max, ok := schemaNumberKeyword(schema, "maximum"); ok && n > max {
+		fail("expected a value <= %v, got %v", max, n)
+	}
+	if min, ok := schemaNumberKeyword(schema, "exclusiveMinimum"); ok && n <= min {
+		fail("expected a value > %v, got %v", min, n)
+	}
+	if max, ok := schemaNumberKeyword(schema, "exclusiveMaximum"); ok && n >= max {
+		fail("expected a value < %v, got %v", max, n)
+	}
+}
+
+// matches returns how many of the schemas v matches.
+func (sv *schemaValidator) matches(schemas []any, v any) int {
+	n := 0
+	for _, s := range schemas {
+		if len(sv.violations(s, v)) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func (sv *schemaValidator) violations(s, v any) []schemaViolation {
+	var out []schemaViolation
+	sv.check(s, v, "", &out)
+	return out
+}
+
+func schemaNumberKeyword(schema map[string]any, key string) (float64, bool) {
+	n, ok := schema[key].(float64)
+	return n, ok
+}
+
+func schemaTypeMatches(t, v any) bool {
+	switch t := t.(type) {
+	case string:
+		vt := schemaValueType(v)
+		return vt == t || (t == schemaNumber && vt == schemaInteger)
+	case []any:
+		for _, tt := range t {
+			if schemaTypeMatches(tt, v) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func schemaTypeList(t any) string {
+	if list, ok := t.([]any); ok {
+		names := make([]string, len(list))
+		for i, n := range list {
+			names[i] = fmt.Sprint(n)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+// schemaValueType returns the JSON Schema type of a decoded JSON value.
+func schemaValueType(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return schemaNull
+	case string:
+		return schemaString
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return schemaInteger
+		}
+		return schemaNumber
+	case bool:
+		return schemaBoolean
+	case map[string]any:
+		return schemaObject
+	case []any:
+		return schemaArray
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func schemaFieldPath(path, name string) string {
+	if len(path) == 0 {
+		return name
+	}
+	return path + "." + name
+}
+
+func schemaShortJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	if s := string(b); len(s) <= 60 {
+		return s
+	}
+	return string(b[:57]) + "..."
+}
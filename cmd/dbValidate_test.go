@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const testTaskSchema = `{
+	"type": "object",
+	"required": ["id", "title"],
+	"additionalProperties": false,
+	"properties": {
+		"id": {"type": "string"},
+		"title": {"type": "string", "minLength": 1},
+		"done": {"type": "boolean"},
+		"priority": {"type": "integer", "minimum": 1, "maximum": 5},
+		"status": {"enum": ["todo", "done"]},
+		"due": {"type": ["string", "null"], "format": "date-time"},
+		"owner": {"$ref": "#/$defs/owner"},
+		"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2}
+	},
+	"$defs": {
+		"owner": {
+			"type": "object",
+			"required": ["email"],
+			"properties": {"email": {"type": "string", "format": "email"}}
+		}
+	}
+}`
+
+func testValidate(t *testing.T, sv *schemaValidator, doc string) []string {
+	t.Helper()
+
+	var v map[string]any
+	if err := json.Unmarshal([]byte(doc), &v); err != nil {
+		t.Fatalf("invalid test document %s: %v", doc, err)
+	}
+
+	var out []string
+	for _, violation := range sv.validate(v) {
+		out = append(out, violation.String())
+	}
+	return out
+}
+
+func TestSchemaValidator(t *testing.T) {
+	sv, err := newSchemaValidator([]byte(testTaskSchema))
+	if err != nil {
+		t.Fatalf("newSchemaValidator returned error: %v", err)
+	}
+
+	if got := testValidate(t, sv, `{"id": "1", "title": "a", "done": true, "priority": 3, "status": "todo", "due": null, "owner": {"email": "ann@example.com"}, "tags": ["x"]}`); len(got) > 0 {
+		t.Fatalf("valid document has violations: %v", got)
+	}
+
+	got := testValidate(t, sv, `{"id": 1, "done": "yes", "priority": 2.5, "status": "late", "due": "tomorrow", "owner": {}, "tags": ["x", 2, "z"], "extra": 1}`)
+	want := []string{
+		`title: missing required field`,
+		`done: expected boolean, got string`,
+		`due: "tomorrow" is not a valid date-time`,
+		`extra: unexpected field`,
+		`id: expected string, got integer`,
+		`owner.email: missing required field`,
+		`priority: expected integer, got number`,
+		`status: "late" is not one of ["todo","done"]`,
+		`tags: expected at most 2 item(s), got 3`,
+		`tags[1]: expected string, got integer`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("violations:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSchemaValidatorCombinators(t *testing.T) {
+	sv, err := newSchemaValidator([]byte(`{
+		"properties": {
+			"a": {"anyOf": [{"type": "string"}, {"type": "integer"}]},
+			"b": {"oneOf": [{"type": "number"}, {"type": "integer"}]},
+			"c": {"not": {"type": "null"}},
+			"d": {"allOf": [{"minimum": 1}, {"maximum": 3}]},
+			"e": {"pattern": "^[a-z]+$"}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("newSchemaValidator returned error: %v", err)
+	}
+
+	got := testValidate(t, sv, `{"a": true, "b": 2, "c": null, "d": 4, "e": "A1"}`)
+	want := []string{
+		`a: does not match any of the anyOf schemas`,
+		`b: matches 2 of the oneOf schemas instead of exactly one`,
+		`c: matches the not schema`,
+		`d: expected a value <= 3, got 4`,
+		`e: "A1" does not match the pattern ^[a-z]+$`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("violations:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestNewSchemaValidatorErrors(t *testing.T) {
+	for _, s := range []string{
+		`[]`,
+		`{"$ref": "other.json#/defs/a"}`,
+		`{"$ref": "#/$defs/missing"}`,
+		`{"properties": {"a": {"pattern": "("}}}`,
+	} {
+		if _, err := newSchemaValidator([]byte(s)); err == nil {
+			t.Fatalf("newSchemaValidator(%s) returned no error", s)
+		}
+	}
+}
+
+func TestRunSchemaFix(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fix commands use a POSIX shell")
+	}
+
+	doc := map[string]any{"id": "1", "title": nil}
+	violations := []schemaViolation{{Path: "title", Message: "expected string, got null"}}
+
+	fixed, err := runSchemaFix(`sed 's/null/"untitled"/'`, doc, violations)
+	if err != nil {
+		t.Fatalf("runSchemaFix returned error: %v", err)
+	}
+	if fixed["title"] != "untitled" {
+		t.Fatalf("fixed title = %v, want untitled", fixed["title"])
+	}
+
+	fixed, err = runSchemaFix(`echo "$BACKEND_VIOLATIONS" | grep -q "title: expected string" && true`, doc, violations)
+	if err != nil || fixed != nil {
+		t.Fatalf("runSchemaFix = %v, %v, want the document skipped", fixed, err)
+	}
+
+	if _, err := runSchemaFix(`echo oops >&2; exit 3`, doc, violations); err == nil || !strings.Contains(err.Error(), "oops") {
+		t.Fatalf("runSchemaFix error = %v, want the command's stderr", err)
+	}
+
+	if _, err := runSchemaFix(`echo not json`, doc, violations); err == nil {
+		t.Fatal("runSchemaFix returned no error for an invalid output")
+	}
+}
@@ -2,24 +2,29 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/staticbackendhq/backend-go"
 )
 
-// dbCmd represents the db command
+// formCmd represents the form command
 var formCmd = &cobra.Command{
 	Use:   "form",
 	Short: "Manage your posted form data.",
 	Long: fmt.Sprintf(`
 %s
 
-You can view and delete form submissions. You'll need a rootToken in your
-config file.
+You can view, export, and delete form submissions. You'll need a rootToken in
+your config file.
 	`,
 		clbold("Manage forms"),
 	),
 	Run: func(cmd *cobra.Command, args []string) {
-
+		_ = cmd.Help()
 	},
 }
 
@@ -36,3 +41,154 @@ func init() {
 	// is called directly, e.g.:
 	// accountCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
+
+// formsRepo is the reserved repository where form submissions are stored.
+const formsRepo = "sb_forms"
+
+// formListMax is the number of submissions "form list" displays at most.
+const formListMax = 100
+
+// formLeadingColumns are displayed first, in this order, when present.
+var formLeadingColumns = []string{"id", "form", "created"}
+
+func addFormSinceFlag(cmd *cobra.Command) {
+	cmd.Flags().String("since", "", "only submissions after a date (2006-01-02) or a duration (24h, 7d)")
+}
+
+// getFormSince returns the zero time when --since is not set.
+func getFormSince(cmd *cobra.Command) (time.Time, bool) {
+	raw, _ := cmd.Flags().GetString("since")
+	if len(raw) == 0 {
+		return time.Time{}, true
+	}
+
+	since, err := parseSince(raw, time.Now())
+	if err != nil {
+		printError("invalid --since: %v", err)
+		return time.Time{}, false
+	}
+
+	return since, true
+}
+
+// parseSince accepts a date, an RFC 3339 timestamp or a duration relative
+// to now. Durations also support a "d" unit for days.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return time.Time{}, fmt.Errorf("%q is not a valid number of days", s)
+		}
+		return now.AddDate(0, 0, -n), nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a date nor a duration", s)
+	}
+
+	return now.Add(-d), nil
+}
+
+// formSubmissions returns the submissions of a form, or of every form when
+// name is empty, most recent first. Unlike ListForm, which only returns the
+// latest 100 submissions, it pages through the forms repository until the
+// submissions are older than since, or limit are returned when it's not 0.
+func formSubmissions(tok, name string, since time.Time, limit int) ([]map[string]interface{}, error) {
+	var filter []backend.QueryItem
+	if len(name) > 0 {
+		filter = append(filter, backend.QueryItem{Field: "form", Op: backend.QueryEqual, Value: name})
+	}
+
+	var all []map[string]interface{}
+	lp := &backend.ListParams{Page: 1, Size: 100, Descending: true}
+	for {
+		var docs []map[string]interface{}
+		var err error
+		if len(filter) > 0 {
+			_, err = backend.SudoFind(tok, formsRepo, filter, &docs, lp)
+		} else {
+			_, err = backend.SudoList(tok, formsRepo, &docs, lp)
+		}
+		if err != nil {
+			return all, err
+		}
+
+		var done bool
+		all, done = formCollect(all, docs, since, limit)
+		if done || len(docs) < lp.Size {
+			return all, nil
+		}
+		lp.Page++
+	}
+}
+
+// formCollect appends a page of submissions to all, done is true once a
+// submission is older than since, the next ones being older still, or when
+// limit submissions are collected. Submissions without a creation time are
+// kept.
+func formCollect(all, docs []map[string]interface{}, since time.Time, limit int) ([]map[string]interface{}, bool) {
+	for _, doc := range docs {
+		if t, ok := formSubmissionTime(doc); ok && !since.IsZero() && t.Before(since) {
+			return all, true
+		}
+
+		all = append(all, doc)
+		if limit > 0 && len(all) >= limit {
+			return all, true
+		}
+	}
+	return all, false
+}
+
+// formSubmissionTime returns the creation time of a submission, ok is false
+// when it cannot be determined.
+func formSubmissionTime(doc map[string]interface{}) (time.Time, bool) {
+	s, ok := doc["created"].(string)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+// formColumns returns a stable column order from the keys of all submissions.
+func formColumns(docs []map[string]interface{}) []string {
+	seen := map[string]struct{}{}
+	for _, doc := range docs {
+		for k := range doc {
+			seen[k] = struct{}{}
+		}
+	}
+
+	var cols []string
+	for _, k := range formLeadingColumns {
+		if _, ok := seen[k]; ok {
+			cols = append(cols, k)
+			delete(seen, k)
+		}
+	}
+
+	rest := make([]string, 0, len(seen))
+	for k := range seen {
+		rest = append(rest, k)
+	}
+	sort.Strings(rest)
+
+	return append(cols, rest...)
+}
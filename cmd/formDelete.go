@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/staticbackendhq/backend-go"
+)
+
+// formDeleteCmd deletes form submissions
+var formDeleteCmd = &cobra.Command{
+	Use:   "delete form-name [id]",
+	Short: "Delete form submissions",
+	Long: fmt.Sprintf(`
+%s
+
+Delete a specific submission by its id, or all submissions of a form with
+%s. Combine %s with %s to only delete older submissions.
+
+$> backend form delete contact submission-id
+$> backend form delete contact --all
+	`,
+		clbold("Delete form submissions"),
+		clbold("--all"),
+		clbold("--all"),
+		clbold("--before"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		if setBackend() == false {
+			return
+		}
+
+		tok, ok := getRootToken()
+		if !ok {
+			return
+		}
+
+		all, _ := cmd.Flags().GetBool("all")
+
+		if len(args) == 0 {
+			printError("Argument missing: form-name — please supply a form name.")
+			return
+		} else if len(args) == 1 && !all {
+			printError("Argument missing: id — please supply a submission id or --all.")
+			return
+		}
+
+		if !all {
+			var doc map[string]interface{}
+			if err := backend.SudoGetByID(tok, formsRepo, args[1], &doc); err != nil {
+				printError("An error occurred: %v", err)
+				return
+			}
+
+			if form, _ := doc["form"].(string); form != args[0] {
+				printError("the submission %s does not belong to the %s form", args[1], args[0])
+				return
+			}
+
+			if err := backend.SudoDelete(tok, formsRepo, args[1]); err != nil {
+				printError("An error occurred: %v", err)
+				return
+			}
+
+			printSuccess("the submission %s has been deleted", args[1])
+			return
+		}
+
+		var before time.Time
+		if raw, _ := cmd.Flags().GetString("before"); len(raw) > 0 {
+			t, err := parseSince(raw, time.Now())
+			if err != nil {
+				printError("invalid --before: %v", err)
+				return
+			}
+			before = t
+		}
+
+		n, err := formDeleteAll(tok, args[0], before)
+		if err != nil {
+			printError("An error occurred after deleting %d submission(s): %v", n, err)
+			return
+		}
+
+		printSuccess("%d submission(s) of %s have been deleted", n, args[0])
+	},
+}
+
+func init() {
+	formCmd.AddCommand(formDeleteCmd)
+
+	formDeleteCmd.Flags().Bool("all", false, "delete all submissions of the form")
+	formDeleteCmd.Flags().String("before", "", "with --all, only submissions before a date (2006-01-02) or a duration ago (24h, 7d)")
+}
+
+// formDeleteAll deletes the submissions of a form. When before is set, only
+// older submissions are deleted. The submissions are listed before deleting
+// them so the deletions do not shift the pages.
+func formDeleteAll(tok, name string, before time.Time) (int, error) {
+	docs, err := formSubmissions(tok, name, time.Time{}, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, doc := range docs {
+		if !before.IsZero() {
+			if t, ok := formSubmissionTime(doc); !ok || !t.Before(before) {
+				continue
+			}
+		}
+
+		id, ok := doc["id"].(string)
+		if !ok {
+			continue
+		}
+
+		if err := backend.SudoDelete(tok, formsRepo, id); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// formExportCmd exports form submissions
+var formExportCmd = &cobra.Command{
+	Use:   "export [form-name]",
+	Short: "Export form submissions as CSV or JSON",
+	Long: fmt.Sprintf(`
+%s
+
+The CSV columns are the union of the fields of all submissions, so
+submissions with different fields line up in the same file.
+
+$> backend form export contact --output csv > contact.csv
+$> backend form export contact --since 7d --output json --file contact.json
+	`,
+		clbold("Export form submissions"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		if setBackend() == false {
+			return
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		if output != "csv" && output != "json" {
+			printError("invalid --output %q: must be csv or json", output)
+			return
+		}
+
+		since, ok := getFormSince(cmd)
+		if !ok {
+			return
+		}
+
+		tok, ok := getRootToken()
+		if !ok {
+			return
+		}
+
+		var name string
+		if len(args) == 1 {
+			name = args[0]
+		}
+
+		results, err := formSubmissions(tok, name, since, 0)
+		if err != nil {
+			printError("An error occurred: %v", err)
+			return
+		}
+
+		var w io.Writer = os.Stdout
+		if dest, _ := cmd.Flags().GetString("file"); len(dest) > 0 {
+			f, err := os.Create(dest)
+			if err != nil {
+				printError("could not create %s: %v", dest, err)
+				return
+			}
+			defer f.Close()
+			w = f
+		}
+
+		if output == "json" {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(results)
+		} else {
+			err = formExportCSV(w, results)
+		}
+		if err != nil {
+			printError("An error occurred: %v", err)
+		}
+	},
+}
+
+func init() {
+	formCmd.AddCommand(formExportCmd)
+
+	formExportCmd.Flags().StringP("output", "o", "csv", "export format: csv or json")
+	formExportCmd.Flags().String("file", "", "write to this file instead of stdout")
+	addFormSinceFlag(formExportCmd)
+}
+
+func formExportCSV(w io.Writer, docs []map[string]interface{}) error {
+	cols := formColumns(docs)
+
+	cw := csv.NewWriter(w)
+	cw.Write(cols)
+	for _, doc := range docs {
+		rec := make([]string, len(cols))
+		for i, col := range cols {
+			v, ok := doc[col]
+			if !ok || v == nil {
+				continue
+			}
+
+			switch v := v.(type) {
+			case string:
+				rec[i] = v
+			case map[string]interface{}, []interface{}:
+				b, _ := json.Marshal(v)
+				rec[i] = string(b)
+			default:
+				rec[i] = fmt.Sprintf("%v", v)
+			}
+		}
+		cw.Write(rec)
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
@@ -2,13 +2,11 @@ package cmd
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/staticbackendhq/backend-go"
 )
 
-// dbListCmd list document in a repository
+// formListCmd list form submissions
 var formListCmd = &cobra.Command{
 	Use:   "list [form-name]",
 	Short: "View form submissions",
@@ -17,10 +15,14 @@ var formListCmd = &cobra.Command{
 
 If you specify a %s only submissions for this form will be listed.
 
-Otherwise, the latest 100 submissions across all your forms will be displayed.
+Otherwise, the submissions across all your forms will be displayed, use
+%s to only list the recent ones. At most the latest %d submissions are
+listed, use "backend form export" to get all of them.
 	`,
 		clbold("List form submissions"),
 		clbold("form-name"),
+		clbold("--since"),
+		formListMax,
 	),
 	Run: func(cmd *cobra.Command, args []string) {
 		if setBackend() == false {
@@ -37,23 +39,24 @@ Otherwise, the latest 100 submissions across all your forms will be displayed.
 			name = args[0]
 		}
 
-		results, err := backend.ListForm(tok, name)
+		since, ok := getFormSince(cmd)
+		if !ok {
+			return
+		}
+
+		formatOpts, err := getDBDocumentFormatOptions(cmd)
+		if err != nil {
+			return
+		}
+
+		results, err := formSubmissions(tok, name, since, formListMax)
 		if err != nil {
 			printError("An error occurred: %v", err)
 			return
 		}
 
 		fmt.Printf("%s result(s)\n\n", clbold(len(results)))
-		for _, doc := range results {
-			o := "{ "
-			for k, v := range doc {
-				o += fmt.Sprintf("%s: %v, ", k, v)
-			}
-
-			o = strings.TrimSuffix(o, ", ") + " }"
-
-			fmt.Println(o)
-		}
+		printDBDocuments(results, formatOpts)
 	},
 }
 
@@ -65,4 +68,6 @@ func init() {
 	// Cobra supports Persistent Flags which will work for this command
 	// and all subcommands, e.g.:
 	// accountCmd.PersistentFlags().String("foo", "", "A help for foo")
+	addFormSinceFlag(formListCmd)
+	addDBDocumentFormatFlag(formListCmd)
 }
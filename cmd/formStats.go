@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// formStatsCmd counts form submissions per day
+var formStatsCmd = &cobra.Command{
+	Use:   "stats [form-name]",
+	Short: "Count form submissions per form per day",
+	Long: fmt.Sprintf(`
+%s
+
+Displays the number of submissions per form and per day, in your local time.
+	`,
+		clbold("Form submission stats"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		if setBackend() == false {
+			return
+		}
+
+		since, ok := getFormSince(cmd)
+		if !ok {
+			return
+		}
+
+		tok, ok := getRootToken()
+		if !ok {
+			return
+		}
+
+		var name string
+		if len(args) == 1 {
+			name = args[0]
+		}
+
+		results, err := formSubmissions(tok, name, since, 0)
+		if err != nil {
+			printError("An error occurred: %v", err)
+			return
+		}
+
+		stats := formStats(results)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
+		fmt.Fprintf(w, "FORM\tDAY\tSUBMISSIONS\n")
+		for _, s := range stats {
+			fmt.Fprintf(w, "%s\t%s\t%d\n", s.form, s.day, s.count)
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	formCmd.AddCommand(formStatsCmd)
+
+	addFormSinceFlag(formStatsCmd)
+}
+
+type formStat struct {
+	form  string
+	day   string
+	count int
+}
+
+// formStats groups submissions by form name and local day, sorted by form
+// then day. Submissions without a creation time are counted as "unknown".
+func formStats(docs []map[string]interface{}) []formStat {
+	counts := map[[2]string]int{}
+	for _, doc := range docs {
+		form, _ := doc["form"].(string)
+
+		day := "unknown"
+		if t, ok := formSubmissionTime(doc); ok {
+			day = t.Local().Format("2006-01-02")
+		}
+
+		counts[[2]string{form, day}]++
+	}
+
+	stats := make([]formStat, 0, len(counts))
+	for k, n := range counts {
+		stats = append(stats, formStat{form: k[0], day: k[1], count: n})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].form != stats[j].form {
+			return stats[i].form < stats[j].form
+		}
+		return stats[i].day < stats[j].day
+	})
+
+	return stats
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]time.Time{
+		"24h":                  now.Add(-24 * time.Hour),
+		"7d":                   now.AddDate(0, 0, -7),
+		"2024-05-01T00:00:00Z": time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	for input, want := range tests {
+		got, err := parseSince(input, now)
+		if err != nil {
+			t.Fatalf("parseSince(%q) returned error: %v", input, err)
+		}
+		if !got.Equal(want) {
+			t.Fatalf("parseSince(%q) = %v, want %v", input, got, want)
+		}
+	}
+
+	if _, err := parseSince("yesterday", now); err == nil {
+		t.Fatal("parseSince returned nil error for an invalid value")
+	}
+}
+
+func TestFormColumns(t *testing.T) {
+	docs := []map[string]interface{}{
+		{"name": "a", "id": "1", "form": "contact"},
+		{"email": "b@acme.com", "created": "2024-05-01T00:00:00Z", "id": "2"},
+	}
+
+	want := []string{"id", "form", "created", "email", "name"}
+	got := formColumns(docs)
+	if len(got) != len(want) {
+		t.Fatalf("formColumns returned %v, want %v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("formColumns returned %v, want %v", got, want)
+		}
+	}
+}
+
+func TestFormCollect(t *testing.T) {
+	docs := []map[string]interface{}{
+		{"id": "new", "created": "2024-05-02T00:00:00Z"},
+		{"id": "unknown"},
+		{"id": "old", "created": "2024-04-01T00:00:00Z"},
+		{"id": "older", "created": "2024-03-01T00:00:00Z"},
+	}
+
+	got, done := formCollect(nil, docs, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), 0)
+	if !done || len(got) != 2 || got[0]["id"] != "new" || got[1]["id"] != "unknown" {
+		t.Fatalf("formCollect with since = %v, %v", got, done)
+	}
+
+	got, done = formCollect(nil, docs, time.Time{}, 3)
+	if !done || len(got) != 3 {
+		t.Fatalf("formCollect with limit = %v, %v", got, done)
+	}
+
+	got, done = formCollect(got[:1], docs[1:2], time.Time{}, 0)
+	if done || len(got) != 2 {
+		t.Fatalf("formCollect = %v, %v, want the page appended", got, done)
+	}
+}
+
+func TestFormExportCSV(t *testing.T) {
+	docs := []map[string]interface{}{
+		{"id": "1", "name": "Dominic", "tags": []interface{}{"a", "b"}},
+		{"id": "2", "email": "d@acme.com"},
+	}
+
+	var buf bytes.Buffer
+	if err := formExportCSV(&buf, docs); err != nil {
+		t.Fatalf("formExportCSV returned error: %v", err)
+	}
+
+	want := "id,email,name,tags\n1,,Dominic,\"[\"\"a\"\",\"\"b\"\"]\"\n2,d@acme.com,,\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("formExportCSV returned %q, want %q", got, want)
+	}
+}
+
+func TestFormStats(t *testing.T) {
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	docs := []map[string]interface{}{
+		{"form": "contact", "created": day.Format(time.RFC3339)},
+		{"form": "contact", "created": day.Format(time.RFC3339)},
+		{"form": "beta", "created": day.Format(time.RFC3339)},
+	}
+
+	stats := formStats(docs)
+	if len(stats) != 2 {
+		t.Fatalf("formStats returned %v, want 2 entries", stats)
+	}
+
+	if stats[0].form != "beta" || stats[0].count != 1 {
+		t.Fatalf("unexpected first stat %+v", stats[0])
+	}
+	if stats[1].form != "contact" || stats[1].day != "2024-05-01" || stats[1].count != 2 {
+		t.Fatalf("unexpected second stat %+v", stats[1])
+	}
+}
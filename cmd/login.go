@@ -27,14 +27,14 @@ We're saving your root token in the .backend.yml file, make sure to add it to yo
 			fmt.Println(err)
 			return
 		}
-		pk := "dev_memory_pk"
+		pk := devPublicKey
 		region := "dev"
-		rtoken := "safe-to-use-in-dev-root-token"
-		email := "admin@dev.com"
-		password := "devpw1234"
+		rtoken := devRootToken
+		email := devAdminEmail
+		password := devAdminPassword
 
 		if dev {
-			fmt.Println("In development, an admin user is already available: admin@dev.com / devpw1234")
+			fmt.Printf("In development, an admin user is already available: %s / %s\n", devAdminEmail, devAdminPassword)
 		} else {
 			var err error
 
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// mailCmd represents the mail command
+var mailCmd = &cobra.Command{
+	Use:   "mail",
+	Short: "Send transactional emails.",
+	Long: fmt.Sprintf(`
+%s
+
+You may send emails with attachments, optionally rendering the HTML body from
+a template. You'll need a rootToken in your config file.
+	`,
+		clbold("Send emails"),
+	),
+}
+
+func init() {
+	rootCmd.AddCommand(mailCmd)
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// mailInboxCmd lists emails captured by the dev server
+var mailInboxCmd = &cobra.Command{
+	Use:   "inbox",
+	Short: "List emails captured by the dev server",
+	Long: fmt.Sprintf(`
+%s
+
+When running, "backend server" captures every email sent via the mail API,
+and the ones the server sends itself like password resets, instead of
+delivering them. Captured emails are saved in the %s directory of
+the folder where the server was started, or the server.dataDir config entry.
+
+You may also browse them at /_inbox/ on the dev server's URL, printed when it
+starts, while the server runs.
+	`,
+		clbold("Dev server inbox"),
+		clbold(defaultDevDataDir),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		mails, err := newDevMailbox().list()
+		if err != nil {
+			printError("error reading inbox: %v", err)
+			return
+		}
+
+		fmt.Printf("%s email(s)\n\n", clbold(len(mails)))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
+		fmt.Fprintf(w, "ID\tRECEIVED\tFROM\tTO\tSUBJECT\n")
+		for i := len(mails) - 1; i >= 0; i-- {
+			m := mails[i]
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n",
+				m.ID,
+				m.Received.Format("2006/01/02 15:04:05"),
+				m.From,
+				m.To,
+				m.Subject,
+			)
+		}
+		w.Flush()
+	},
+}
+
+var mailInboxShowCmd = &cobra.Command{
+	Use:   "show id",
+	Short: "Display a captured email",
+	Run: func(cmd *cobra.Command, args []string) {
+		m, ok := mailInboxGet(args)
+		if !ok {
+			return
+		}
+
+		fmt.Printf("Received: %s\n", m.Received.Format("2006/01/02 15:04:05"))
+		mailPrintSummary(m.EmailData)
+		fmt.Println(m.Body)
+	},
+}
+
+var mailInboxOpenCmd = &cobra.Command{
+	Use:   "open id",
+	Short: "Open the HTML body of a captured email in your browser",
+	Run: func(cmd *cobra.Command, args []string) {
+		m, ok := mailInboxGet(args)
+		if !ok {
+			return
+		}
+
+		dest := filepath.Join(os.TempDir(), fmt.Sprintf("backend-mail-%d.html", m.ID))
+		if err := os.WriteFile(dest, []byte(m.Body), 0644); err != nil {
+			printError("could not write %s: %v", dest, err)
+			return
+		}
+
+		if err := openBrowser(dest); err != nil {
+			printError("unable to open your browser: %v", err)
+			fmt.Printf("The email body was written to %s\n", dest)
+		}
+	},
+}
+
+var mailInboxClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete all captured emails",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := newDevMailbox().clear(); err != nil {
+			printError("error clearing inbox: %v", err)
+			return
+		}
+
+		printSuccess("the inbox has been cleared")
+	},
+}
+
+func init() {
+	mailCmd.AddCommand(mailInboxCmd)
+
+	mailInboxCmd.AddCommand(mailInboxShowCmd)
+	mailInboxCmd.AddCommand(mailInboxOpenCmd)
+	mailInboxCmd.AddCommand(mailInboxClearCmd)
+}
+
+func mailInboxGet(args []string) (devMail, bool) {
+	if len(args) != 1 {
+		printError("argument mismatch: one email id should be specified")
+		return devMail{}, false
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		printError("invalid email id %q", args[0])
+		return devMail{}, false
+	}
+
+	m, err := newDevMailbox().get(id)
+	if err != nil {
+		printError("error reading email %d: %v", id, err)
+		return devMail{}, false
+	}
+
+	return m, true
+}
+
+func openBrowser(target string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", target).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", target).Start()
+	default:
+		return exec.Command("xdg-open", target).Start()
+	}
+}
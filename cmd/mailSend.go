@@ -0,0 +1,340 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/staticbackendhq/backend-go"
+)
+
+// mailSendCmd sends an email
+var mailSendCmd = &cobra.Command{
+	Use:   "send",
+	Short: "Send an email",
+	Long: fmt.Sprintf(`
+%s
+
+The HTML body comes from %s, %s, or a Go html/template file rendered with %s.
+
+backend mail send --from me@acme.com --to you@acme.com --subject "Hi" --html-file ./hi.html
+backend mail send --from me@acme.com --to you@acme.com --subject "Invoice" --html-file ./invoice.html --attach ./invoice.pdf
+
+Templates receive the JSON from %s (or %s) as {{.Data}} and, when %s is
+set, all the matching documents as {{.Results}}:
+
+backend mail send --from me@acme.com --to you@acme.com --subject "Report" \
+	--template ./report.html --data '{"name":"Dominic"}' \
+	--data-repo tasks --data-filter "done == false"
+
+Use %s to write the rendered HTML to a file instead of sending the email.
+	`,
+		clbold("Send an email"),
+		clbold("--body"),
+		clbold("--html-file"),
+		clbold("--template"),
+		clbold("--data"),
+		clbold("--data-file"),
+		clbold("--data-repo"),
+		clbold("--preview"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		if setBackend() == false {
+			return
+		}
+
+		email, ok := mailFromFlags(cmd)
+		if !ok {
+			return
+		}
+
+		preview, err := cmd.Flags().GetString("preview")
+		if err != nil {
+			printError("unable to read --preview option: %v", err)
+			return
+		}
+
+		needsToken := len(preview) == 0
+		if repo, _ := cmd.Flags().GetString("data-repo"); len(repo) > 0 {
+			needsToken = true
+		}
+
+		var tok string
+		if needsToken {
+			tok, ok = getRootToken()
+			if !ok {
+				return
+			}
+		}
+
+		body, ok := mailBody(cmd, tok)
+		if !ok {
+			return
+		}
+		email.Body = body
+
+		if len(preview) > 0 {
+			if err := os.WriteFile(preview, []byte(email.Body), 0644); err != nil {
+				printError("could not write %s: %v", preview, err)
+				return
+			}
+
+			mailPrintSummary(email)
+			printSuccess("email preview written to %s", preview)
+			return
+		}
+
+		if _, err := backend.SendMailWithAttachments(tok, email); err != nil {
+			printError("error sending your email: %v", err)
+			return
+		}
+
+		printSuccess("Email sent to %s", clbold(email.To))
+	},
+}
+
+func init() {
+	mailCmd.AddCommand(mailSendCmd)
+
+	mailSendCmd.Flags().String("from", "", "sender email address")
+	mailSendCmd.Flags().String("from-name", "", "sender display name")
+	mailSendCmd.Flags().String("to", "", "recipient email address")
+	mailSendCmd.Flags().String("reply-to", "", "optional reply-to email address")
+	mailSendCmd.Flags().String("subject", "", "email subject")
+	mailSendCmd.Flags().String("body", "", "HTML body")
+	mailSendCmd.Flags().String("html-file", "", "path of an HTML file used as body")
+	mailSendCmd.Flags().String("template", "", "path of a Go html/template file used as body")
+	mailSendCmd.Flags().String("data", "{}", "JSON value passed to the template as .Data")
+	mailSendCmd.Flags().String("data-file", "", "path of a JSON file passed to the template as .Data")
+	mailSendCmd.Flags().String("data-repo", "", "repository queried for the template .Results")
+	mailSendCmd.Flags().StringSlice("data-filter", nil, "filter applied to --data-repo, ex: \"done == true\"")
+	mailSendCmd.Flags().StringSlice("attach", nil, "file path or URL to attach, may be repeated")
+	mailSendCmd.Flags().String("preview", "", "write the rendered HTML to this path instead of sending")
+}
+
+// mailTemplateData is what templates receive when rendering a body.
+type mailTemplateData struct {
+	Data    any
+	Results []map[string]interface{}
+}
+
+func mailFromFlags(cmd *cobra.Command) (backend.EmailData, bool) {
+	var email backend.EmailData
+
+	required := []struct {
+		flag string
+		dst  *string
+	}{
+		{"from", &email.From},
+		{"to", &email.To},
+		{"subject", &email.Subject},
+	}
+	for _, r := range required {
+		v, err := cmd.Flags().GetString(r.flag)
+		if err != nil || len(v) == 0 {
+			printError("missing parameter: the --%s option is required", r.flag)
+			return email, false
+		}
+		*r.dst = v
+	}
+
+	email.FromName, _ = cmd.Flags().GetString("from-name")
+	email.ReplyTo, _ = cmd.Flags().GetString("reply-to")
+
+	attachments, err := cmd.Flags().GetStringSlice("attach")
+	if err != nil {
+		printError("unable to read --attach option: %v", err)
+		return email, false
+	}
+
+	for _, a := range attachments {
+		att, err := mailAttachment(a)
+		if err != nil {
+			printError("error reading attachment: %v", err)
+			return email, false
+		}
+		email.Attachments = append(email.Attachments, att)
+	}
+
+	return email, true
+}
+
+func mailBody(cmd *cobra.Command, tok string) (string, bool) {
+	tmpl, _ := cmd.Flags().GetString("template")
+	htmlFile, _ := cmd.Flags().GetString("html-file")
+	body, _ := cmd.Flags().GetString("body")
+
+	switch {
+	case len(tmpl) > 0:
+		b, err := os.ReadFile(tmpl)
+		if err != nil {
+			printError("error reading template file: %v", err)
+			return "", false
+		}
+
+		data, ok := mailTemplateDataFromFlags(cmd, tok)
+		if !ok {
+			return "", false
+		}
+
+		out, err := mailRenderTemplate(filepath.Base(tmpl), string(b), data)
+		if err != nil {
+			printError("error rendering template: %v", err)
+			return "", false
+		}
+		return out, true
+	case len(htmlFile) > 0:
+		b, err := os.ReadFile(htmlFile)
+		if err != nil {
+			printError("error reading HTML file: %v", err)
+			return "", false
+		}
+		return string(b), true
+	case len(body) > 0:
+		return body, true
+	}
+
+	printError("missing parameter: one of --body, --html-file or --template is required")
+	return "", false
+}
+
+func mailTemplateDataFromFlags(cmd *cobra.Command, tok string) (mailTemplateData, bool) {
+	var data mailTemplateData
+
+	raw, _ := cmd.Flags().GetString("data")
+	dataFile, _ := cmd.Flags().GetString("data-file")
+	if len(dataFile) > 0 {
+		b, err := os.ReadFile(dataFile)
+		if err != nil {
+			printError("error reading data file: %v", err)
+			return data, false
+		}
+		raw = string(b)
+	}
+
+	if err := json.Unmarshal([]byte(raw), &data.Data); err != nil {
+		printError("invalid JSON data: %v", err)
+		return data, false
+	}
+
+	repo, _ := cmd.Flags().GetString("data-repo")
+	if len(repo) == 0 {
+		return data, true
+	}
+
+	rawFilters, _ := cmd.Flags().GetStringSlice("data-filter")
+	filters, err := mailQueryFilters(rawFilters)
+	if err != nil {
+		printError("invalid --data-filter: %v", err)
+		return data, false
+	}
+
+	data.Results, err = mailQueryRepo(tok, repo, filters)
+	if err != nil {
+		printError("error querying %s: %v", repo, err)
+		return data, false
+	}
+
+	return data, true
+}
+
+// mailQueryRepo returns every document of repo matching the filters.
+func mailQueryRepo(tok, repo string, filters []backend.QueryItem) ([]map[string]interface{}, error) {
+	var all []map[string]interface{}
+	lp := &backend.ListParams{Page: 1, Size: 100}
+	for {
+		var docs []map[string]interface{}
+		var err error
+		if len(filters) == 0 {
+			_, err = backend.SudoList(tok, repo, &docs, lp)
+		} else {
+			_, err = backend.SudoFind(tok, repo, filters, &docs, lp)
+		}
+		if err != nil {
+			return all, err
+		}
+
+		all = append(all, docs...)
+
+		if len(docs) < lp.Size {
+			return all, nil
+		}
+		lp.Page++
+	}
+}
+
+// mailQueryFilters converts "field op value" strings into query items.
+func mailQueryFilters(raw []string) ([]backend.QueryItem, error) {
+	var args []string
+	for _, f := range raw {
+		parts := strings.Fields(f)
+		if len(parts) < 3 {
+			return nil, fmt.Errorf("filter %q must be in the form \"field op value\"", f)
+		}
+
+		value := strings.Join(parts[2:], " ")
+		args = append(args, parts[0], parts[1], strings.Trim(value, `"`))
+	}
+
+	return argsToQueryItem(args)
+}
+
+func mailRenderTemplate(name, src string, data mailTemplateData) (string, error) {
+	t, err := template.New(name).Parse(src)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func mailAttachment(src string) (backend.Attachment, error) {
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		return backend.Attachment{URL: src}, nil
+	}
+
+	b, err := os.ReadFile(src)
+	if err != nil {
+		return backend.Attachment{}, err
+	}
+
+	ct := mime.TypeByExtension(filepath.Ext(src))
+	if len(ct) == 0 {
+		ct = http.DetectContentType(b)
+	}
+
+	return backend.Attachment{
+		Body:        b,
+		ContentType: ct,
+		Filename:    filepath.Base(src),
+	}, nil
+}
+
+func mailPrintSummary(email backend.EmailData) {
+	fmt.Printf("From: %s <%s>\n", email.FromName, email.From)
+	fmt.Printf("To: %s\n", email.To)
+	if len(email.ReplyTo) > 0 {
+		fmt.Printf("Reply-To: %s\n", email.ReplyTo)
+	}
+	fmt.Printf("Subject: %s\n", email.Subject)
+	for _, a := range email.Attachments {
+		if len(a.URL) > 0 {
+			fmt.Printf("Attachment: %s\n", a.URL)
+		} else {
+			fmt.Printf("Attachment: %s (%s, %d bytes)\n", a.Filename, a.ContentType, len(a.Body))
+		}
+	}
+	fmt.Println()
+}
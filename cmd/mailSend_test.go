@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/staticbackendhq/backend-go"
+)
+
+func TestMailRenderTemplate(t *testing.T) {
+	data := mailTemplateData{
+		Data:    map[string]interface{}{"name": "Dominic"},
+		Results: []map[string]interface{}{{"title": "task 1"}, {"title": "<b>task 2</b>"}},
+	}
+
+	src := `Hi {{.Data.name}}{{range .Results}}, {{.title}}{{end}}`
+	want := "Hi Dominic, task 1, &lt;b&gt;task 2&lt;/b&gt;"
+
+	got, err := mailRenderTemplate("test", src, data)
+	if err != nil {
+		t.Fatalf("mailRenderTemplate returned error: %v", err)
+	}
+	if got != want {
+		t.Fatalf("mailRenderTemplate returned %q, want %q", got, want)
+	}
+}
+
+func TestMailQueryFilters(t *testing.T) {
+	filters, err := mailQueryFilters([]string{"done == true", `name = "Dominic St-Pierre"`})
+	if err != nil {
+		t.Fatalf("mailQueryFilters returned error: %v", err)
+	}
+
+	want := []backend.QueryItem{
+		{Field: "done", Op: backend.QueryEqual, Value: "true"},
+		{Field: "name", Op: backend.QueryEqual, Value: "Dominic St-Pierre"},
+	}
+	if len(filters) != len(want) {
+		t.Fatalf("mailQueryFilters returned %v, want %v", filters, want)
+	}
+
+	for i := range want {
+		if filters[i] != want[i] {
+			t.Fatalf("mailQueryFilters returned %v, want %v", filters, want)
+		}
+	}
+}
+
+func TestMailQueryFiltersRejectsIncompleteFilter(t *testing.T) {
+	if _, err := mailQueryFilters([]string{"done =="}); err == nil {
+		t.Fatal("mailQueryFilters returned nil error for incomplete filter")
+	}
+}
+
+func TestMailAttachmentURL(t *testing.T) {
+	att, err := mailAttachment("https://example.com/file.pdf")
+	if err != nil {
+		t.Fatalf("mailAttachment returned error: %v", err)
+	}
+	if att.URL != "https://example.com/file.pdf" || len(att.Body) > 0 {
+		t.Fatalf("mailAttachment returned %+v, want a URL attachment", att)
+	}
+}
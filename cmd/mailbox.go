@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/mail"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/staticbackendhq/backend-go"
+)
+
+var errMailNotFound = errors.New("email not found")
+
+// devMail is an email captured by the dev server.
+type devMail struct {
+	ID       int       `json:"id"`
+	Received time.Time `json:"received"`
+	backend.EmailData
+}
+
+// devMailbox stores captured emails as NDJSON.
+type devMailbox struct {
+	mu   sync.Mutex
+	path string
+}
+
+func newDevMailbox() *devMailbox {
+	return &devMailbox{path: filepath.Join(devDataDir(), "mail.ndjson")}
+}
+
+func (mb *devMailbox) add(email backend.EmailData) (devMail, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	mails, err := mb.readAll()
+	if err != nil {
+		return devMail{}, err
+	}
+
+	m := devMail{
+		ID:        len(mails) + 1,
+		Received:  time.Now(),
+		EmailData: email,
+	}
+
+	return m, appendNDJSON(mb.path, m)
+}
+
+func (mb *devMailbox) list() ([]devMail, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	return mb.readAll()
+}
+
+func (mb *devMailbox) get(id int) (devMail, error) {
+	mails, err := mb.list()
+	if err != nil {
+		return devMail{}, err
+	}
+
+	for _, m := range mails {
+		if m.ID == id {
+			return m, nil
+		}
+	}
+
+	return devMail{}, errMailNotFound
+}
+
+func (mb *devMailbox) clear() error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	return removeIfExists(mb.path)
+}
+
+func (mb *devMailbox) readAll() ([]devMail, error) {
+	var mails []devMail
+	err := readNDJSON(mb.path, func(line []byte) error {
+		var m devMail
+		if err := json.Unmarshal(line, &m); err != nil {
+			return err
+		}
+		mails = append(mails, m)
+		return nil
+	})
+	return mails, err
+}
+
+// captureHandler replaces the send mail endpoint of the dev server.
+func (mb *devMailbox) captureHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var email backend.EmailData
+		if err := json.NewDecoder(r.Body).Decode(&email); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		m, err := mb.add(email)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		fmt.Printf("email captured #%d to %s: %s\n", m.ID, m.To, m.Subject)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, "true")
+	})
+}
+
+// sesHandler captures the emails StaticBackend sends itself, like the
+// password reset ones. The dev server points its SES mail provider at this
+// handler, which answers the SendEmail action of the SES API.
+func (mb *devMailbox) sesHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			devSESError(w, "InvalidParameterValue", err.Error())
+			return
+		}
+
+		if action := r.PostForm.Get("Action"); action != "SendEmail" {
+			devSESError(w, "InvalidAction", fmt.Sprintf("the dev server does not support the %q action", action))
+			return
+		}
+
+		email := backend.EmailData{
+			From:    r.PostForm.Get("Source"),
+			Subject: r.PostForm.Get("Message.Subject.Data"),
+			Body:    r.PostForm.Get("Message.Body.Html.Data"),
+			ReplyTo: r.PostForm.Get("ReplyToAddresses.member.1"),
+		}
+		if len(email.Body) == 0 {
+			email.Body = r.PostForm.Get("Message.Body.Text.Data")
+		}
+		if addr, err := mail.ParseAddress(email.From); err == nil {
+			email.FromName, email.From = addr.Name, addr.Address
+		}
+
+		var to []string
+		for i := 1; ; i++ {
+			addr := r.PostForm.Get(fmt.Sprintf("Destination.ToAddresses.member.%d", i))
+			if len(addr) == 0 {
+				break
+			}
+			to = append(to, addr)
+		}
+		email.To = strings.Join(to, ", ")
+
+		m, err := mb.add(email)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		fmt.Printf("email captured #%d to %s: %s\n", m.ID, m.To, m.Subject)
+
+		w.Header().Set("Content-Type", "text/xml")
+		fmt.Fprintf(w, `<SendEmailResponse xmlns="http://ses.amazonaws.com/doc/2010-12-01/">
+<SendEmailResult><MessageId>dev-%d</MessageId></SendEmailResult>
+<ResponseMetadata><RequestId>dev-%d</RequestId></ResponseMetadata>
+</SendEmailResponse>`, m.ID, m.ID)
+	})
+}
+
+func devSESError(w http.ResponseWriter, code, msg string) {
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(http.StatusBadRequest)
+	fmt.Fprintf(w, `<ErrorResponse><Error><Type>Sender</Type><Code>%s</Code><Message>%s</Message></Error></ErrorResponse>`,
+		template.HTMLEscapeString(code), template.HTMLEscapeString(msg))
+}
+
+var devMailboxTmpl = template.Must(template.New("inbox").Parse(`<!doctype html>
+<html>
+<head><meta charset="utf-8"><title>Dev inbox</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+td, th { text-align: left; padding: .4em .8em; border-bottom: 1px solid #ddd; }
+</style>
+</head>
+<body>
+<h1>Dev inbox</h1>
+<p>{{len .}} email(s)</p>
+<table>
+<tr><th>#</th><th>RECEIVED</th><th>FROM</th><th>TO</th><th>SUBJECT</th></tr>
+{{range .}}<tr>
+<td>{{.ID}}</td>
+<td>{{.Received.Format "2006/01/02 15:04:05"}}</td>
+<td>{{.From}}</td>
+<td>{{.To}}</td>
+<td><a href="/_inbox/{{.ID}}">{{.Subject}}</a></td>
+</tr>{{end}}
+</table>
+</body>
+</html>`))
+
+// webHandler serves the inbox listing at /_inbox/ and email bodies at
+// /_inbox/{id}.
+func (mb *devMailbox) webHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/_inbox"), "/")
+		if len(rest) == 0 {
+			mails, err := mb.list()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			// newest first
+			for i, j := 0, len(mails)-1; i < j; i, j = i+1, j-1 {
+				mails[i], mails[j] = mails[j], mails[i]
+			}
+
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			if err := devMailboxTmpl.Execute(w, mails); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		id, err := strconv.Atoi(rest)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		m, err := mb.get(id)
+		if errors.Is(err, errMailNotFound) {
+			http.NotFound(w, r)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, m.Body)
+	})
+}
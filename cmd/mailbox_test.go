@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/staticbackendhq/backend-go"
+)
+
+func TestDevMailboxAddAndGet(t *testing.T) {
+	mb := &devMailbox{path: filepath.Join(t.TempDir(), "mail.ndjson")}
+
+	for _, subject := range []string{"first", "second"} {
+		if _, err := mb.add(backend.EmailData{To: "admin@dev.com", Subject: subject}); err != nil {
+			t.Fatalf("add returned error: %v", err)
+		}
+	}
+
+	mails, err := mb.list()
+	if err != nil {
+		t.Fatalf("list returned error: %v", err)
+	}
+	if len(mails) != 2 {
+		t.Fatalf("list returned %d emails, want 2", len(mails))
+	}
+
+	m, err := mb.get(2)
+	if err != nil {
+		t.Fatalf("get returned error: %v", err)
+	}
+	if m.Subject != "second" {
+		t.Fatalf("get returned subject %q, want %q", m.Subject, "second")
+	}
+
+	if _, err := mb.get(3); err != errMailNotFound {
+		t.Fatalf("get returned %v, want errMailNotFound", err)
+	}
+
+	if err := mb.clear(); err != nil {
+		t.Fatalf("clear returned error: %v", err)
+	}
+	if mails, _ := mb.list(); len(mails) != 0 {
+		t.Fatalf("list after clear returned %d emails", len(mails))
+	}
+}
+
+func TestDevMailboxSESHandler(t *testing.T) {
+	mb := &devMailbox{path: filepath.Join(t.TempDir(), "mail.ndjson")}
+	srv := httptest.NewServer(mb.sesHandler())
+	defer srv.Close()
+
+	form := url.Values{
+		"Action":                           {"SendEmail"},
+		"Source":                           {"Acme <no-reply@acme.com>"},
+		"Destination.ToAddresses.member.1": {"alice@acme.com"},
+		"Destination.ToAddresses.member.2": {"bob@acme.com"},
+		"Message.Subject.Data":             {"Reset your password"},
+		"Message.Body.Html.Data":           {"<p>code 1234</p>"},
+	}
+
+	resp, err := http.PostForm(srv.URL, form)
+	if err != nil {
+		t.Fatalf("POST returned error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("SendEmail returned %s", resp.Status)
+	}
+
+	m, err := mb.get(1)
+	if err != nil {
+		t.Fatalf("get returned error: %v", err)
+	}
+	if m.From != "no-reply@acme.com" || m.FromName != "Acme" || m.To != "alice@acme.com, bob@acme.com" ||
+		m.Subject != "Reset your password" || !strings.Contains(m.Body, "1234") {
+		t.Fatalf("captured %+v", m.EmailData)
+	}
+
+	resp, err = http.PostForm(srv.URL, url.Values{"Action": {"SendRawEmail"}})
+	if err != nil {
+		t.Fatalf("POST returned error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("SendRawEmail returned %s, want 400", resp.Status)
+	}
+}
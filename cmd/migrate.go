@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/staticbackendhq/backend-go"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Reshape your documents with versioned migrations.",
+	Long: fmt.Sprintf(`
+%s
+
+Migrations are files applied in order to the documents of a repository. Each
+one changes the documents with an up function, and optionally reverts them
+with a down function. The applied migrations are recorded in a repository of
+the database, %s by default, so every environment knows its migration state.
+
+$> backend migrate create add_priority --repo tasks
+$> backend migrate status
+$> backend migrate up
+$> backend migrate down
+
+Migrations are JavaScript files:
+
+	var repo = "tasks";
+	var indexes = ["priority"];
+
+	// optional, only the matching documents are migrated up
+	function filter(doc) { return doc.priority === undefined; }
+
+	function up(doc) { doc.priority = 3; return doc; }
+	function down(doc) { delete doc.priority; return doc; }
+
+or YAML files whose filter, up and down are Go templates rendering the
+document as JSON, with the set, unset, rename, has, default and json
+functions:
+
+	repo: tasks
+	indexes: [priority]
+	filter: '{{ not (has . "priority") }}'
+	up: '{{ set . "priority" 3 | json }}'
+	down: '{{ unset . "priority" | json }}'
+
+Returning nothing, or rendering an empty template, leaves the document
+unchanged. Updates merge the fields, so removed fields are set to null, and
+the migrations receive the documents without their null fields. The filter
+only applies when migrating up, down is called for every document. The
+indexes are added before migrating up and kept when migrating down.
+
+Documents are updated one at a time, write migrations that can run again on
+documents already migrated should one fail midway.
+	`,
+		clbold("Data migrations"),
+		migrateDefaultLedger,
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+const (
+	migrateDefaultDir    = "migrations"
+	migrateDefaultLedger = "schema_migrations"
+)
+
+// migrationFileRe matches the migration files: a version, a name and the
+// extension of their language.
+var migrationFileRe = regexp.MustCompile(`^(\d{14})_([a-z0-9_]+)\.(js|yml)$`)
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.PersistentFlags().String("dir", migrateDefaultDir, "directory of the migration files")
+	migrateCmd.PersistentFlags().String("ledger", migrateDefaultLedger, "repository recording the applied migrations")
+}
+
+// migration is a migration file.
+type migration struct {
+	Version  string
+	Name     string
+	Path     string
+	Checksum string
+	script   migrationScript
+}
+
+// migrationRecord is a document of the ledger repository.
+type migrationRecord struct {
+	ID        string    `json:"id,omitempty"`
+	Version   string    `json:"version"`
+	Name      string    `json:"name"`
+	Repo      string    `json:"repo"`
+	Checksum  string    `json:"checksum"`
+	Documents int       `json:"documents"`
+	AppliedAt time.Time `json:"appliedAt"`
+}
+
+// loadMigrations returns the migrations of dir sorted by version, parsing
+// their scripts.
+func loadMigrations(dir string) ([]migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []migration
+	seen := map[string]string{}
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+
+		m := migrationFileRe.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("%s: invalid migration file name, expected <version>_<name>.js or .yml", e.Name())
+		}
+
+		if other, ok := seen[m[1]]; ok {
+			return nil, fmt.Errorf("%s and %s have the same version", other, e.Name())
+		}
+		seen[m[1]] = e.Name()
+
+		path := filepath.Join(dir, e.Name())
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var script migrationScript
+		if m[3] == "js" {
+			script, err = newJSMigration(e.Name(), string(b))
+		} else {
+			script, err = newTemplateMigration(e.Name(), b)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+
+		if len(script.repo()) == 0 {
+			return nil, fmt.Errorf("%s: the migration has no repo", e.Name())
+		}
+
+		sum := sha256.Sum256(b)
+		list = append(list, migration{
+			Version:  m[1],
+			Name:     m[2],
+			Path:     path,
+			Checksum: hex.EncodeToString(sum[:]),
+			script:   script,
+		})
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// migrateOptions are the flags shared by the migrate commands.
+type migrateOptions struct {
+	dir    string
+	ledger string
+}
+
+func getMigrateOptions(cmd *cobra.Command) migrateOptions {
+	var opts migrateOptions
+	opts.dir, _ = cmd.Flags().GetString("dir")
+	opts.ledger, _ = cmd.Flags().GetString("ledger")
+	return opts
+}
+
+// readMigrationLedger returns the applied migrations by version.
+func readMigrationLedger(tok, ledger string) (map[string]migrationRecord, error) {
+	applied := map[string]migrationRecord{}
+
+	// listing a repository that does not exist yet may fail
+	repos, err := backend.SudoListRepositories(tok)
+	if err != nil {
+		return nil, err
+	}
+
+	found := false
+	for _, r := range repos {
+		if r == ledger {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return applied, nil
+	}
+
+	lp := &backend.ListParams{Page: 1, Size: 100}
+	for {
+		var records []migrationRecord
+		if _, err := backend.SudoList(tok, ledger, &records, lp); err != nil {
+			return nil, err
+		}
+
+		for _, r := range records {
+			applied[r.Version] = r
+		}
+
+		if len(records) < lp.Size {
+			return applied, nil
+		}
+		lp.Page++
+	}
+}
+
+// migrateStatus is the state of a migration compared to the ledger.
+type migrateStatus struct {
+	Migration *migration
+	Record    *migrationRecord
+}
+
+func (s migrateStatus) version() string {
+	if s.Migration != nil {
+		return s.Migration.Version
+	}
+	return s.Record.Version
+}
+
+func (s migrateStatus) label() string {
+	switch {
+	case s.Record == nil:
+		return "pending"
+	case s.Migration == nil:
+		return "applied, file missing"
+	case s.Record.Checksum != s.Migration.Checksum:
+		return "applied, file changed"
+	}
+	return "applied"
+}
+
+// migrateStatuses merges the migration files and the ledger, sorted by
+// version.
+func migrateStatuses(list []migration, applied map[string]migrationRecord) []migrateStatus {
+	var statuses []migrateStatus
+	seen := map[string]bool{}
+
+	for i := range list {
+		s := migrateStatus{Migration: &list[i]}
+		if r, ok := applied[list[i].Version]; ok {
+			s.Record = &r
+		}
+		seen[list[i].Version] = true
+		statuses = append(statuses, s)
+	}
+
+	for v, r := range applied {
+		if !seen[v] {
+			r := r
+			statuses = append(statuses, migrateStatus{Record: &r})
+		}
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].version() < statuses[j].version() })
+	return statuses
+}
+
+// runMigration applies a migration in a direction to every document of its
+// repository and returns the number of changed documents. Nothing is
+// updated when dryRun is set.
+func runMigration(tok string, m migration, direction string, dryRun bool) (int, error) {
+	repo := m.script.repo()
+
+	if direction == migrateUp && !dryRun {
+		for _, field := range m.script.indexes() {
+			if err := backend.SudoAddIndex(tok, repo, field); err != nil {
+				return 0, fmt.Errorf("unable to add the %s index: %w", field, err)
+			}
+		}
+	}
+
+	changed := 0
+	_, err := dbEachDoc(tok, false, repo, 0, func(doc map[string]any) error {
+		id, _ := doc["id"].(string)
+
+		body, err := migrateDoc(m.script, direction, doc)
+		if err != nil {
+			return fmt.Errorf("document %s: %w", id, err)
+		}
+		if body == nil {
+			return nil
+		}
+
+		changed++
+		if dryRun {
+			return nil
+		}
+
+		var updated map[string]any
+		if err := dbUpdateDoc(tok, false, repo, id, body, &updated); err != nil {
+			return fmt.Errorf("unable to update document %s: %w", id, err)
+		}
+		return nil
+	})
+
+	return changed, err
+}
+
+// migrateDoc applies a migration in a direction to a document and returns
+// the fields to update, nil when the document is unchanged. The removed
+// fields are null, so the script receives the document without them and a
+// migration can run again after being reverted.
+func migrateDoc(script migrationScript, direction string, doc map[string]any) (map[string]any, error) {
+	present := dbPresentFields(doc)
+
+	out, err := script.transform(direction, present)
+	if err != nil || out == nil {
+		return nil, err
+	}
+
+	body := dbUpdateBody(present, out)
+	if len(body) == 0 {
+		return nil, nil
+	}
+	return body, nil
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var migrateCreateCmd = &cobra.Command{
+	Use:   "create name --repo repo-name",
+	Short: "Create a migration file",
+	Long: fmt.Sprintf(`
+%s
+
+Creates a migration file named after the current time and the provided name
+in the migrations directory, as JavaScript or as a YAML file with Go
+templates when --type yml is used.
+
+$> backend migrate create add_priority --repo tasks
+$> backend migrate create rename_title --repo tasks --type yml
+	`,
+		clbold("Create a migration"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			printError("Argument missing: name — please supply a migration name.")
+			return
+		}
+
+		name := migrationName(strings.Join(args, "_"))
+		if len(name) == 0 {
+			printError("invalid migration name %q: use letters, digits and underscores", strings.Join(args, " "))
+			return
+		}
+
+		repo, _ := cmd.Flags().GetString("repo")
+		if len(repo) == 0 {
+			printError("Flag missing: --repo — please supply the repository to migrate.")
+			return
+		}
+
+		typ, _ := cmd.Flags().GetString("type")
+		var content string
+		switch typ {
+		case "js":
+			content = fmt.Sprintf(migrateJSTemplate, repo)
+		case "yml":
+			content = fmt.Sprintf(migrateYAMLTemplate, repo)
+		default:
+			printError("invalid --type %q: must be js or yml", typ)
+			return
+		}
+
+		opts := getMigrateOptions(cmd)
+		if err := os.MkdirAll(opts.dir, 0755); err != nil {
+			printError("unable to create %s: %v", opts.dir, err)
+			return
+		}
+
+		version := time.Now().UTC().Format("20060102150405")
+		path := filepath.Join(opts.dir, fmt.Sprintf("%s_%s.%s", version, name, typ))
+
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err != nil {
+			printError("unable to create %s: %v", path, err)
+			return
+		}
+		defer f.Close()
+
+		if _, err := f.WriteString(content); err != nil {
+			printError("unable to write %s: %v", path, err)
+			return
+		}
+
+		printSuccess("migration created: %s", path)
+	},
+}
+
+func init() {
+	migrateCmd.AddCommand(migrateCreateCmd)
+
+	migrateCreateCmd.Flags().String("repo", "", "repository the migration applies to")
+	migrateCreateCmd.Flags().String("type", "js", "migration language: js or yml for Go templates")
+}
+
+var migrationNameRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// migrationName converts a name to the lower case, underscore separated
+// name of the migration files.
+func migrationName(s string) string {
+	return strings.Trim(migrationNameRe.ReplaceAllString(strings.ToLower(s), "_"), "_")
+}
+
+const migrateJSTemplate = `var repo = %q;
+
+// fields to index before migrating up
+var indexes = [];
+
+// only the documents for which filter returns true are migrated, remove it
+// to migrate them all
+function filter(doc) {
+  return true;
+}
+
+// up returns the migrated document, or nothing to leave it unchanged
+function up(doc) {
+  return doc;
+}
+
+// down reverts up, remove it if the migration cannot be reverted
+function down(doc) {
+  return doc;
+}
+`
+
+const migrateYAMLTemplate = `repo: %q
+
+# fields to index before migrating up
+indexes: []
+
+# only the documents rendering true are migrated, remove it to migrate them
+# all
+filter: '{{ true }}'
+
+# up renders the migrated document as JSON, or nothing to leave it unchanged
+up: '{{ json . }}'
+
+# down reverts up, remove it if the migration cannot be reverted
+down: '{{ json . }}'
+`
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/staticbackendhq/backend-go"
+)
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Revert the latest applied migrations",
+	Long: fmt.Sprintf(`
+%s
+
+Reverts the latest applied migration, or the --steps latest ones, with their
+down function or template, and removes them from the applied migrations.
+The indexes added by the migrations are kept.
+
+$> backend migrate down
+$> backend migrate down --steps 3 --dry-run
+	`,
+		clbold("Revert migrations"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		if setBackend() == false {
+			os.Exit(1)
+		}
+
+		steps, _ := cmd.Flags().GetInt("steps")
+		if steps <= 0 {
+			printError("--steps must be greater than 0")
+			os.Exit(1)
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		tok, ok := getRootToken()
+		if !ok {
+			os.Exit(1)
+		}
+
+		opts := getMigrateOptions(cmd)
+
+		list, err := loadMigrations(opts.dir)
+		if err != nil {
+			printError("unable to load the migrations: %v", err)
+			os.Exit(1)
+		}
+
+		applied, err := readMigrationLedger(tok, opts.ledger)
+		if err != nil {
+			printError("unable to read the %s repository: %v", opts.ledger, err)
+			os.Exit(1)
+		}
+
+		var revert []migrateStatus
+		for _, s := range migrateStatuses(list, applied) {
+			if s.Record != nil {
+				revert = append(revert, s)
+			}
+		}
+		sort.Slice(revert, func(i, j int) bool { return revert[i].version() > revert[j].version() })
+
+		if len(revert) == 0 {
+			fmt.Println("No applied migrations")
+			return
+		}
+		if len(revert) > steps {
+			revert = revert[:steps]
+		}
+
+		// every migration must be revertible before changing anything
+		for _, s := range revert {
+			if s.Migration == nil {
+				printError("%s_%s cannot be reverted: its file is missing", s.Record.Version, s.Record.Name)
+				os.Exit(1)
+			}
+			if !s.Migration.script.hasDown() {
+				printError("%s_%s cannot be reverted: it has no down", s.Migration.Version, s.Migration.Name)
+				os.Exit(1)
+			}
+		}
+
+		for _, s := range revert {
+			m := *s.Migration
+			fmt.Printf("Reverting %s_%s on %s...\n", m.Version, m.Name, m.script.repo())
+
+			n, err := runMigration(tok, m, migrateDown, dryRun)
+			if err != nil {
+				printError("%s_%s failed after changing %d document(s): %v", m.Version, m.Name, n, err)
+				os.Exit(1)
+			}
+
+			if dryRun {
+				fmt.Printf("  %d document(s) would change\n", n)
+				continue
+			}
+
+			if err := backend.SudoDelete(tok, opts.ledger, s.Record.ID); err != nil {
+				printError("%s_%s was reverted but is still recorded in %s: %v", m.Version, m.Name, opts.ledger, err)
+				os.Exit(1)
+			}
+
+			fmt.Printf("  %d document(s) changed\n", n)
+			if len(m.script.indexes()) > 0 {
+				printWarning("the indexes added by %s_%s are kept", m.Version, m.Name)
+			}
+		}
+
+		if dryRun {
+			printSuccess("%d migration(s) checked, nothing was changed", len(revert))
+			return
+		}
+		printSuccess("%d migration(s) reverted", len(revert))
+	},
+}
+
+func init() {
+	migrateCmd.AddCommand(migrateDownCmd)
+
+	migrateDownCmd.Flags().Int("steps", 1, "number of migrations to revert")
+	migrateDownCmd.Flags().Bool("dry-run", false, "count the documents to change without updating them")
+}
@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dop251/goja"
+	"gopkg.in/yaml.v2"
+)
+
+// migrateScriptTimeout stops a migration function stuck on a document.
+const migrateScriptTimeout = 10 * time.Second
+
+// migrationScript transforms the documents of a migration. It returns the
+// changed document, or nil to leave it unchanged. The filter only selects
+// the documents to migrate up, down reverts every document it changes.
+type migrationScript interface {
+	repo() string
+	indexes() []string
+	hasDown() bool
+	transform(direction string, doc map[string]any) (map[string]any, error)
+}
+
+// Directions of a migration, also the name of their function or template.
+const (
+	migrateUp   = "up"
+	migrateDown = "down"
+)
+
+// jsMigration runs a JavaScript migration:
+//
+//	var repo = "tasks";
+//	var indexes = ["priority"];
+//
+//	function filter(doc) { return doc.priority === undefined; }
+//	function up(doc) { doc.priority = 3; return doc; }
+//	function down(doc) { delete doc.priority; return doc; }
+type jsMigration struct {
+	vm         *goja.Runtime
+	repoName   string
+	indexNames []string
+	funcs      map[string]goja.Callable
+}
+
+func newJSMigration(name, src string) (*jsMigration, error) {
+	vm := goja.New()
+
+	console := vm.NewObject()
+	console.Set("log", func(args ...any) {
+		fmt.Println(args...)
+	})
+	vm.Set("console", console)
+
+	if _, err := vm.RunScript(name, src); err != nil {
+		return nil, err
+	}
+
+	m := &jsMigration{vm: vm, funcs: map[string]goja.Callable{}}
+
+	// evaluating the names also finds the ones declared with let or const
+	if v, err := vm.RunString("typeof repo === 'undefined' ? '' : repo"); err == nil {
+		m.repoName = v.String()
+	}
+	if v, err := vm.RunString("typeof indexes === 'undefined' ? [] : indexes"); err == nil {
+		if err := vm.ExportTo(v, &m.indexNames); err != nil {
+			return nil, fmt.Errorf("indexes must be an array of field names: %w", err)
+		}
+	}
+
+	for _, fn := range []string{"filter", migrateUp, migrateDown} {
+		v, err := vm.RunString(fmt.Sprintf("typeof %s === 'undefined' ? undefined : %s", fn, fn))
+		if err != nil || goja.IsUndefined(v) {
+			continue
+		}
+
+		call, ok := goja.AssertFunction(v)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a function", fn)
+		}
+		m.funcs[fn] = call
+	}
+
+	if _, ok := m.funcs[migrateUp]; !ok {
+		return nil, fmt.Errorf("the migration has no up function")
+	}
+
+	return m, nil
+}
+
+func (m *jsMigration) repo() string      { return m.repoName }
+func (m *jsMigration) indexes() []string { return m.indexNames }
+
+func (m *jsMigration) hasDown() bool {
+	_, ok := m.funcs[migrateDown]
+	return ok
+}
+
+func (m *jsMigration) transform(direction string, doc map[string]any) (map[string]any, error) {
+	fn, ok := m.funcs[direction]
+	if !ok {
+		return nil, fmt.Errorf("the migration has no %s function", direction)
+	}
+
+	timer := time.AfterFunc(migrateScriptTimeout, func() {
+		m.vm.Interrupt(fmt.Sprintf("%s took more than %v", direction, migrateScriptTimeout))
+	})
+	defer timer.Stop()
+	defer m.vm.ClearInterrupt()
+
+	if filter, ok := m.funcs["filter"]; ok && direction == migrateUp {
+		v, err := filter(goja.Undefined(), m.vm.ToValue(migrateCopyDoc(doc)))
+		if err != nil {
+			return nil, err
+		}
+		if !v.ToBoolean() {
+			return nil, nil
+		}
+	}
+
+	// the function may change its argument in place
+	v, err := fn(goja.Undefined(), m.vm.ToValue(migrateCopyDoc(doc)))
+	if err != nil {
+		return nil, err
+	}
+
+	if goja.IsUndefined(v) || goja.IsNull(v) {
+		return nil, nil
+	}
+
+	return migrateChangedDoc(doc, v.Export())
+}
+
+// templateMigration runs a migration whose filter, up and down are Go
+// templates rendering the document as JSON:
+//
+//	repo: tasks
+//	indexes: [priority]
+//	filter: '{{ not (has . "priority") }}'
+//	up: '{{ set . "priority" 3 | json }}'
+//	down: '{{ unset . "priority" | json }}'
+type templateMigration struct {
+	file      templateMigrationFile
+	templates map[string]*template.Template
+}
+
+type templateMigrationFile struct {
+	Repo    string   `yaml:"repo"`
+	Indexes []string `yaml:"indexes"`
+	Filter  string   `yaml:"filter"`
+	Up      string   `yaml:"up"`
+	Down    string   `yaml:"down"`
+}
+
+// migrateTemplateFuncs are the functions available in the templates, the
+// ones changing a document return a copy.
+var migrateTemplateFuncs = template.FuncMap{
+	"set": func(doc map[string]any, key string, value any) map[string]any {
+		out := migrateCopyDoc(doc)
+		out[key] = value
+		return out
+	},
+	"unset": func(doc map[string]any, keys ...string) map[string]any {
+		out := migrateCopyDoc(doc)
+		for _, k := range keys {
+			delete(out, k)
+		}
+		return out
+	},
+	"rename": func(doc map[string]any, from, to string) map[string]any {
+		out := migrateCopyDoc(doc)
+		if v, ok := out[from]; ok {
+			out[to] = v
+			delete(out, from)
+		}
+		return out
+	},
+	"has": func(doc map[string]any, key string) bool {
+		_, ok := doc[key]
+		return ok
+	},
+	"default": func(fallback, v any) any {
+		if v == nil || v == "" {
+			return fallback
+		}
+		return v
+	},
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+	"now": func() string {
+		return time.Now().UTC().Format(time.RFC3339)
+	},
+}
+
+func newTemplateMigration(name string, src []byte) (*templateMigration, error) {
+	m := &templateMigration{templates: map[string]*template.Template{}}
+	if err := yaml.UnmarshalStrict(src, &m.file); err != nil {
+		return nil, err
+	}
+
+	if len(strings.TrimSpace(m.file.Up)) == 0 {
+		return nil, fmt.Errorf("the migration has no up template")
+	}
+
+	for fn, text := range map[string]string{"filter": m.file.Filter, migrateUp: m.file.Up, migrateDown: m.file.Down} {
+		if len(strings.TrimSpace(text)) == 0 {
+			continue
+		}
+
+		tmpl, err := template.New(name + ":" + fn).Funcs(migrateTemplateFuncs).Option("missingkey=zero").Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		m.templates[fn] = tmpl
+	}
+
+	return m, nil
+}
+
+func (m *templateMigration) repo() string      { return m.file.Repo }
+func (m *templateMigration) indexes() []string { return m.file.Indexes }
+
+func (m *templateMigration) hasDown() bool {
+	_, ok := m.templates[migrateDown]
+	return ok
+}
+
+func (m *templateMigration) transform(direction string, doc map[string]any) (map[string]any, error) {
+	tmpl, ok := m.templates[direction]
+	if !ok {
+		return nil, fmt.Errorf("the migration has no %s template", direction)
+	}
+
+	if filter, ok := m.templates["filter"]; ok && direction == migrateUp {
+		out, err := migrateRender(filter, doc)
+		if err != nil {
+			return nil, err
+		}
+		if out != "true" {
+			return nil, nil
+		}
+	}
+
+	out, err := migrateRender(tmpl, doc)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+
+	var changed map[string]any
+	if err := json.Unmarshal([]byte(out), &changed); err != nil {
+		return nil, fmt.Errorf("the %s template must render a JSON document: %w", direction, err)
+	}
+
+	return migrateChangedDoc(doc, changed)
+}
+
+func migrateRender(tmpl *template.Template, doc map[string]any) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, migrateCopyDoc(doc)); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// migrateCopyDoc returns a deep copy of a document, normalized as decoded
+// from JSON.
+func migrateCopyDoc(doc map[string]any) map[string]any {
+	var out map[string]any
+	b, err := json.Marshal(doc)
+	if err == nil {
+		err = json.Unmarshal(b, &out)
+	}
+	if err != nil || out == nil {
+		return map[string]any{}
+	}
+	return out
+}
+
+// migrateChangedDoc returns the document a migration produced, normalized as
+// decoded from JSON, or nil when it equals the original.
+func migrateChangedDoc(orig map[string]any, v any) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+
+	var changed map[string]any
+	if err := json.Unmarshal(b, &changed); err != nil {
+		return nil, fmt.Errorf("the migration must return a document: %w", err)
+	}
+
+	if reflect.DeepEqual(migrateCopyDoc(orig), changed) {
+		return nil, nil
+	}
+	return changed, nil
+}
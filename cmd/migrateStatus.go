@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "List the migrations and whether they are applied",
+	Long: fmt.Sprintf(`
+%s
+
+Compares the migration files with the migrations recorded as applied in the
+database. A migration whose file changed after it was applied is flagged, so
+is an applied migration whose file is missing.
+	`,
+		clbold("Migration status"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		if setBackend() == false {
+			return
+		}
+
+		tok, ok := getRootToken()
+		if !ok {
+			return
+		}
+
+		opts := getMigrateOptions(cmd)
+
+		list, err := loadMigrations(opts.dir)
+		if err != nil {
+			printError("unable to load the migrations: %v", err)
+			return
+		}
+
+		applied, err := readMigrationLedger(tok, opts.ledger)
+		if err != nil {
+			printError("unable to read the %s repository: %v", opts.ledger, err)
+			return
+		}
+
+		statuses := migrateStatuses(list, applied)
+		if len(statuses) == 0 {
+			fmt.Printf("No migrations in %s\n", opts.dir)
+			return
+		}
+
+		pending := 0
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tREPO\tSTATUS\tAPPLIED AT\tDOCUMENTS")
+		for _, s := range statuses {
+			name, repo, appliedAt, docs := "", "", "", ""
+			if s.Migration != nil {
+				name, repo = s.Migration.Name, s.Migration.script.repo()
+			} else {
+				name, repo = s.Record.Name, s.Record.Repo
+			}
+			if s.Record != nil {
+				appliedAt = s.Record.AppliedAt.Local().Format(time.RFC822)
+				docs = fmt.Sprint(s.Record.Documents)
+			} else {
+				pending++
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", s.version(), name, repo, s.label(), appliedAt, docs)
+		}
+		w.Flush()
+
+		fmt.Printf("\n%s pending migration(s)\n", clbold(pending))
+	},
+}
+
+func init() {
+	migrateCmd.AddCommand(migrateStatusCmd)
+}
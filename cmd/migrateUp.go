@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/staticbackendhq/backend-go"
+)
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply the pending migrations",
+	Long: fmt.Sprintf(`
+%s
+
+Applies the pending migrations in version order and records them as applied.
+Use --to to stop at a version and --dry-run to count the documents each
+migration would change without updating them.
+
+$> backend migrate up
+$> backend migrate up --to 20261019150000 --dry-run
+	`,
+		clbold("Apply migrations"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		if setBackend() == false {
+			os.Exit(1)
+		}
+
+		tok, ok := getRootToken()
+		if !ok {
+			os.Exit(1)
+		}
+
+		opts := getMigrateOptions(cmd)
+		to, _ := cmd.Flags().GetString("to")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		list, err := loadMigrations(opts.dir)
+		if err != nil {
+			printError("unable to load the migrations: %v", err)
+			os.Exit(1)
+		}
+
+		applied, err := readMigrationLedger(tok, opts.ledger)
+		if err != nil {
+			printError("unable to read the %s repository: %v", opts.ledger, err)
+			os.Exit(1)
+		}
+
+		var pending []migration
+		for _, s := range migrateStatuses(list, applied) {
+			if s.label() == "applied, file changed" {
+				printWarning("%s_%s changed after it was applied", s.Migration.Version, s.Migration.Name)
+			}
+			if s.Record == nil && (len(to) == 0 || s.Migration.Version <= to) {
+				pending = append(pending, *s.Migration)
+			}
+		}
+
+		if len(pending) == 0 {
+			fmt.Println("No pending migrations")
+			return
+		}
+
+		for _, m := range pending {
+			fmt.Printf("Applying %s_%s to %s...\n", m.Version, m.Name, m.script.repo())
+
+			n, err := runMigration(tok, m, migrateUp, dryRun)
+			if err != nil {
+				printError("%s_%s failed after changing %d document(s): %v", m.Version, m.Name, n, err)
+				os.Exit(1)
+			}
+
+			if dryRun {
+				fmt.Printf("  %d document(s) would change\n", n)
+				continue
+			}
+
+			record := migrationRecord{
+				Version:   m.Version,
+				Name:      m.Name,
+				Repo:      m.script.repo(),
+				Checksum:  m.Checksum,
+				Documents: n,
+				AppliedAt: time.Now().UTC(),
+			}
+
+			var created migrationRecord
+			if err := backend.SudoCreate(tok, opts.ledger, record, &created); err != nil {
+				printError("%s_%s was applied but could not be recorded in %s: %v", m.Version, m.Name, opts.ledger, err)
+				os.Exit(1)
+			}
+
+			fmt.Printf("  %d document(s) changed\n", n)
+		}
+
+		if dryRun {
+			printSuccess("%d migration(s) checked, nothing was changed", len(pending))
+			return
+		}
+		printSuccess("%d migration(s) applied", len(pending))
+	},
+}
+
+func init() {
+	migrateCmd.AddCommand(migrateUpCmd)
+
+	migrateUpCmd.Flags().String("to", "", "last version to apply (default all)")
+	migrateUpCmd.Flags().Bool("dry-run", false, "count the documents to change without updating them")
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSMigration(t *testing.T) {
+	m, err := newJSMigration("add_priority.js", `
+		const repo = "tasks";
+		let indexes = ["priority"];
+
+		function filter(doc) { return doc.done !== true; }
+		function up(doc) { doc.priority = doc.title.length > 3 ? 1 : 2; delete doc.legacy; return doc; }
+		function down(doc) { delete doc.priority; return doc; }
+	`)
+	if err != nil {
+		t.Fatalf("newJSMigration returned error: %v", err)
+	}
+
+	if m.repo() != "tasks" || !reflect.DeepEqual(m.indexes(), []string{"priority"}) || !m.hasDown() {
+		t.Fatalf("repo = %q, indexes = %v, hasDown = %v", m.repo(), m.indexes(), m.hasDown())
+	}
+
+	doc := map[string]any{"id": "1", "title": "write", "legacy": true}
+	out, err := m.transform(migrateUp, doc)
+	if err != nil {
+		t.Fatalf("transform returned error: %v", err)
+	}
+	if want := map[string]any{"id": "1", "title": "write", "priority": float64(1)}; !reflect.DeepEqual(out, want) {
+		t.Fatalf("up = %v, want %v", out, want)
+	}
+	if _, ok := doc["priority"]; ok {
+		t.Fatal("up changed the original document")
+	}
+
+	if out, err := m.transform(migrateUp, map[string]any{"id": "2", "title": "x", "done": true}); err != nil || out != nil {
+		t.Fatalf("filtered document = %v, %v, want it unchanged", out, err)
+	}
+
+	if out, err := m.transform(migrateDown, map[string]any{"id": "3", "title": "x"}); err != nil || out != nil {
+		t.Fatalf("down without changes = %v, %v, want nil", out, err)
+	}
+
+	if _, err := newJSMigration("bad.js", `var repo = "tasks";`); err == nil {
+		t.Fatal("newJSMigration accepted a migration without up")
+	}
+
+	failing, err := newJSMigration("fail.js", `var repo = "tasks"; function up(doc) { throw new Error("boom"); }`)
+	if err != nil {
+		t.Fatalf("newJSMigration returned error: %v", err)
+	}
+	if _, err := failing.transform(migrateUp, doc); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("transform error = %v, want boom", err)
+	}
+	if _, err := failing.transform(migrateDown, doc); err == nil {
+		t.Fatal("transform down returned no error without a down function")
+	}
+}
+
+func TestTemplateMigration(t *testing.T) {
+	m, err := newTemplateMigration("rename_title.yml", []byte(`
+repo: tasks
+indexes: [name]
+filter: '{{ has . "title" }}'
+up: '{{ rename . "title" "name" | json }}'
+down: '{{ rename . "name" "title" | json }}'
+`))
+	if err != nil {
+		t.Fatalf("newTemplateMigration returned error: %v", err)
+	}
+
+	out, err := m.transform(migrateUp, map[string]any{"id": "1", "title": "write"})
+	if err != nil {
+		t.Fatalf("transform returned error: %v", err)
+	}
+	if want := map[string]any{"id": "1", "name": "write"}; !reflect.DeepEqual(out, want) {
+		t.Fatalf("up = %v, want %v", out, want)
+	}
+
+	if out, err := m.transform(migrateUp, map[string]any{"id": "2", "name": "done"}); err != nil || out != nil {
+		t.Fatalf("filtered document = %v, %v, want it unchanged", out, err)
+	}
+
+	set, err := newTemplateMigration("set.yml", []byte(`
+repo: tasks
+up: '{{ set . "priority" (default 3 .priority) | json }}'
+`))
+	if err != nil {
+		t.Fatalf("newTemplateMigration returned error: %v", err)
+	}
+	out, err = set.transform(migrateUp, map[string]any{"id": "1"})
+	if err != nil || out["priority"] != float64(3) {
+		t.Fatalf("up = %v, %v, want priority 3", out, err)
+	}
+
+	for _, src := range []string{`repo: tasks`, `up: '{{ json . }}'` + "\nunknown: 1", `up: '{{ json . '`} {
+		if _, err := newTemplateMigration("bad.yml", []byte(src)); err == nil {
+			t.Fatalf("newTemplateMigration(%q) returned no error", src)
+		}
+	}
+}
+
+func TestMigrateDocUpDownUp(t *testing.T) {
+	js, err := newJSMigration("add_priority.js", `
+		var repo = "tasks";
+		function filter(doc) { return doc.priority === undefined; }
+		function up(doc) { doc.priority = 3; return doc; }
+		function down(doc) { delete doc.priority; return doc; }
+	`)
+	if err != nil {
+		t.Fatalf("newJSMigration returned error: %v", err)
+	}
+
+	yml, err := newTemplateMigration("add_priority.yml", []byte(`
+repo: tasks
+filter: '{{ not (has . "priority") }}'
+up: '{{ set . "priority" 3 | json }}'
+down: '{{ unset . "priority" | json }}'
+`))
+	if err != nil {
+		t.Fatalf("newTemplateMigration returned error: %v", err)
+	}
+
+	for name, script := range map[string]migrationScript{"js": js, "yml": yml} {
+		doc := map[string]any{"id": "1", "accountId": "a", "title": "write"}
+
+		// apply merges the update body like the server does
+		apply := func(direction string, want any) {
+			t.Helper()
+
+			body, err := migrateDoc(script, direction, doc)
+			if err != nil {
+				t.Fatalf("%s: %s returned error: %v", name, direction, err)
+			}
+			if body == nil {
+				t.Fatalf("%s: %s left the document unchanged", name, direction)
+			}
+			for k, v := range body {
+				doc[k] = v
+			}
+			if doc["priority"] != want {
+				t.Fatalf("%s: after %s priority = %v, want %v", name, direction, doc["priority"], want)
+			}
+		}
+
+		apply(migrateUp, float64(3))
+		apply(migrateDown, nil)
+		apply(migrateUp, float64(3))
+
+		if body, err := migrateDoc(script, migrateUp, doc); err != nil || body != nil {
+			t.Fatalf("%s: up on a migrated document = %v, %v, want it unchanged", name, body, err)
+		}
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "20261019120000_rename.yml"), "repo: tasks\nup: '{{ json . }}'\n")
+	writeTestFile(t, filepath.Join(dir, "20261018120000_add_priority.js"), `var repo = "tasks"; function up(doc) { return doc; }`)
+	writeTestFile(t, filepath.Join(dir, ".DS_Store"), "")
+
+	list, err := loadMigrations(dir)
+	if err != nil {
+		t.Fatalf("loadMigrations returned error: %v", err)
+	}
+	if len(list) != 2 || list[0].Name != "add_priority" || list[1].Version != "20261019120000" {
+		t.Fatalf("loadMigrations = %+v, want both migrations sorted by version", list)
+	}
+
+	applied := map[string]migrationRecord{
+		"20261018120000": {Version: "20261018120000", Checksum: "old"},
+		"20261017120000": {Version: "20261017120000", Name: "removed"},
+	}
+
+	var labels []string
+	for _, s := range migrateStatuses(list, applied) {
+		labels = append(labels, s.version()+" "+s.label())
+	}
+	want := []string{
+		"20261017120000 applied, file missing",
+		"20261018120000 applied, file changed",
+		"20261019120000 pending",
+	}
+	if !reflect.DeepEqual(labels, want) {
+		t.Fatalf("statuses = %v, want %v", labels, want)
+	}
+
+	for name, content := range map[string]string{
+		"add-index.js":                   `var repo = "tasks"; function up(doc) { return doc; }`,
+		"20261019120000_norepo.js":       `function up(doc) { return doc; }`,
+		"20261019120000_duplicate.js":    `var repo = "tasks"; function up(doc) { return doc; }`,
+		"20261019130000_syntax_error.js": `function up(doc) {`,
+	} {
+		bad := t.TempDir()
+		writeTestFile(t, filepath.Join(bad, "20261019120000_rename.yml"), "repo: tasks\nup: '{{ json . }}'\n")
+		writeTestFile(t, filepath.Join(bad, name), content)
+
+		if _, err := loadMigrations(bad); err == nil {
+			t.Fatalf("loadMigrations accepted %s", name)
+		}
+	}
+}
+
+func TestMigrationName(t *testing.T) {
+	tests := map[string]string{
+		"Add Priority": "add_priority",
+		"rename-title": "rename_title",
+		"__x__":        "x",
+		"!!":           "",
+	}
+
+	for in, want := range tests {
+		if got := migrationName(in); got != want {
+			t.Fatalf("migrationName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"net/url"
 	"strings"
 
 	"github.com/staticbackendhq/backend-go"
@@ -27,3 +28,21 @@ func normalizeBackendRegion(region string) string {
 
 	return "https://" + region
 }
+
+// isLocalRegion reports whether a normalized region targets a local server.
+func isLocalRegion(region string) bool {
+	if region == backend.RegionLocalDev {
+		return true
+	}
+
+	u, err := url.Parse(region)
+	if err != nil {
+		return false
+	}
+
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
@@ -27,3 +27,19 @@ func TestCleanConfigValue(t *testing.T) {
 		t.Fatalf("cleanConfigValue returned %q", got)
 	}
 }
+
+func TestIsLocalRegion(t *testing.T) {
+	tests := map[string]bool{
+		"dev":                           true,
+		"http://localhost:8099":         true,
+		"http://127.0.0.1:9000":         true,
+		"https://na1.staticbackend.dev": false,
+		"https://example.com":           false,
+	}
+
+	for input, want := range tests {
+		if got := isLocalRegion(input); got != want {
+			t.Fatalf("isLocalRegion(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
@@ -1,18 +1,46 @@
 package cmd
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
-	"log"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
-var proxyTarget string
+// proxyOptions are the proxy settings read from its flags.
+type proxyOptions struct {
+	host          string
+	port          string
+	tls           bool
+	corsOrigins   []string
+	target        *url.URL
+	logFile       string
+	noLog         bool
+	captureBodies bool
+	maxBodySize   int
+	redact        bool
+	redactHeaders []string
+	history       int
+	record        string
+	replay        string
+	rulesFile     string
+	faultsFile    string
+	readOnly      bool
+	allow         []string
+	// fault is set by the fault flags, applied after the faults file.
+	fault proxyFault
+}
 
 // proxyCmd represents the proxy command
 var proxyCmd = &cobra.Command{
@@ -29,10 +57,99 @@ backend without deploying your application changes yet.
 
 All requests are proxy as-is, and the responses sent to you without any
 modifications.
+
+Every exchange is logged with its method, path, status, latency and sizes.
+Use --log-file to also save them as NDJSON and --capture-bodies to include
+the request and response bodies. The SB-PUBLIC-KEY, Authorization and cookie
+headers are redacted unless --no-redact is used.
+
+Use --record to save every exchange to a HAR file, and --replay to serve the
+responses of a HAR file without network access. Requests are matched by
+method, path, query and body, identical requests get the recorded responses
+in order:
+
+$> backend proxy --record session.har
+$> backend proxy --replay session.har
+
+The HAR file is written every few seconds and when the proxy stops. Bodies
+are recorded up to --max-body-size, the exchanges with a longer body are not
+replayed. The bodies of the login, register,
+password and token endpoints are redacted unless --no-redact is used, those
+requests are replayed by path regardless of their body. The other bodies are
+recorded as-is, keep the HAR file somewhere safe.
+
+Use --rules to split the traffic between several targets, for instance DB
+writes to a local dev server and everything else to production. Rules are
+checked in order, a path ending with * matches by prefix, the matching target
+is returned in the X-Proxy-Target response header:
+
+	targets:
+	  local:
+	    url: http://localhost:8080
+	    pubKey: dev_memory_pk
+	  prod:
+	    url: na1
+	rules:
+	  - path: /db/*
+	    methods: [POST, PUT, PATCH, DELETE]
+	    target: local
+	  - path: /fn/*
+	    target: prod
+	default: prod
+
+Without default, unmatched requests go to the region of your config file.
+
+Use the fault flags to test how your application handles a slow or failing
+backend. --latency and --jitter delay the requests, --error-rate and
+--drop-rate respond with a random 5xx or close the connection for a share of
+the requests, and --rate-limit responds with 429 above a number of requests
+per second. They apply to the requests matching --fault-path:
+
+$> backend proxy --latency 200ms --jitter 300ms --error-rate 0.1 --fault-path "/db/*"
+
+Use --faults for different faults per path and method, the first matching
+entry applies:
+
+	faults:
+	  - path: /db/*
+	    methods: [GET]
+	    latency: 200ms
+	    errorRate: 0.1
+	    errorStatus: 503
+	  - path: /fn/*
+	    dropRate: 0.05
+	    rateLimit: 5
+
+Injected responses have an X-Proxy-Fault header and the number of injected
+faults is printed when the proxy exits.
+
+Use --read-only when pointing a local app at production, requests that may
+change data are rejected with a 403 response. GET requests and the POST
+endpoints reading data, like queries and counts, are relayed. Use --allow to
+permit other requests by path, optionally preceded by methods:
+
+$> backend proxy --read-only --allow "POST /fn/exec/*" --allow /login
+
+A warning is printed at startup when a target is a production region.
+
+The proxy listens on localhost, use --host 0.0.0.0 to accept requests from
+other devices. Use --tls to serve HTTPS with a self-signed certificate, for
+instance to test secure cookies, and --cors-origin to allow a frontend served
+from another port:
+
+$> backend proxy --tls --cors-origin http://localhost:3000
+
+The recent exchanges are listed at /_proxy/ on the proxy's URL, printed when
+it starts, while the proxy runs.
 	`, clbold("Proxy requests to production")),
 	Run: func(cmd *cobra.Command, args []string) {
-		f := cmd.Flag("port")
-		startProxy(f.Value.String())
+		opts, err := getProxyOptions(cmd)
+		if err != nil {
+			printError("%v", err)
+			os.Exit(1)
+		}
+
+		startProxy(opts)
 	},
 }
 
@@ -48,37 +165,298 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	proxyCmd.Flags().Int32P("port", "p", 8099, "port for the proxy server")
+	proxyCmd.Flags().String("host", "localhost", "address the proxy server listens on")
+	proxyCmd.Flags().Bool("tls", false, "serve HTTPS with a self-signed certificate")
+	proxyCmd.Flags().StringSlice("cors-origin", nil, "allowed CORS origins, may be repeated (default the target's CORS headers)")
+	proxyCmd.Flags().String("log-file", "", "append every exchange to this NDJSON file")
+	proxyCmd.Flags().Bool("no-log", false, "prevents printing requests/responses info")
+	proxyCmd.Flags().Bool("capture-bodies", false, "include request and response bodies in the logs")
+	proxyCmd.Flags().Int("max-body-size", 64*1024, "maximum bytes of a captured body")
+	proxyCmd.Flags().Bool("no-redact", false, "log the SB-PUBLIC-KEY, Authorization and cookie headers as-is")
+	proxyCmd.Flags().StringSlice("redact-header", nil, "additional header to redact, may be repeated")
+	proxyCmd.Flags().Int("history", 200, "number of exchanges kept for the /_proxy/ page")
+	proxyCmd.Flags().String("record", "", "save every exchange to this HAR file")
+	proxyCmd.Flags().String("rules", "", "YAML file routing requests to different targets by path and method")
+	proxyCmd.Flags().String("replay", "", "serve the responses recorded in this HAR file instead of relaying requests")
+	proxyCmd.Flags().String("faults", "", "YAML file injecting latency, errors, dropped connections and rate limits by path and method")
+	proxyCmd.Flags().Duration("latency", 0, "delay added to each request matching --fault-path")
+	proxyCmd.Flags().Duration("jitter", 0, "maximum random delay added to the latency")
+	proxyCmd.Flags().Float64("error-rate", 0, "share of requests answered with a 5xx error, between 0 and 1")
+	proxyCmd.Flags().Int("error-status", 0, "status of the injected errors, a random 5xx by default")
+	proxyCmd.Flags().Float64("drop-rate", 0, "share of requests whose connection is closed without a response, between 0 and 1")
+	proxyCmd.Flags().Int("rate-limit", 0, "requests per second allowed before responding with 429")
+	proxyCmd.Flags().Bool("read-only", false, "reject the requests that may change data")
+	proxyCmd.Flags().StringArray("allow", nil, `request permitted in read-only mode, "[METHODS] /path", may be repeated`)
+	proxyCmd.Flags().String("fault-path", "*", "path the fault flags apply to, a trailing * matches by prefix")
 }
 
-func startProxy(port string) {
+func getProxyOptions(cmd *cobra.Command) (proxyOptions, error) {
+	var opts proxyOptions
+
+	opts.record, _ = cmd.Flags().GetString("record")
+	opts.replay, _ = cmd.Flags().GetString("replay")
+	if len(opts.record) > 0 && len(opts.replay) > 0 {
+		return opts, fmt.Errorf("--record and --replay cannot be used together")
+	}
+
+	opts.rulesFile, _ = cmd.Flags().GetString("rules")
+	if len(opts.rulesFile) > 0 && len(opts.replay) > 0 {
+		return opts, fmt.Errorf("--rules and --replay cannot be used together")
+	}
+
+	// no network access is needed when replaying, and the rules file may
+	// define its own default target
 	region := cleanConfigValue(viper.GetString("region"))
-	if len(region) == 0 {
-		printError("Missing a region config entry in your config file")
-		os.Exit(1)
+	if len(region) > 0 && len(opts.replay) == 0 {
+		t, err := url.Parse(normalizeBackendRegion(region))
+		if err != nil {
+			return opts, fmt.Errorf("invalid proxy target: %w", err)
+		}
+		opts.target = t
+	} else if len(region) == 0 && len(opts.replay) == 0 && len(opts.rulesFile) == 0 {
+		return opts, fmt.Errorf("Missing a region config entry in your config file")
 	}
 
-	proxyTarget = normalizeBackendRegion(region)
+	opts.port = cmd.Flag("port").Value.String()
+	opts.host, _ = cmd.Flags().GetString("host")
+	opts.tls, _ = cmd.Flags().GetBool("tls")
+	opts.corsOrigins, _ = cmd.Flags().GetStringSlice("cors-origin")
+	opts.logFile, _ = cmd.Flags().GetString("log-file")
+	opts.noLog, _ = cmd.Flags().GetBool("no-log")
+	opts.captureBodies, _ = cmd.Flags().GetBool("capture-bodies")
+	opts.maxBodySize, _ = cmd.Flags().GetInt("max-body-size")
+	opts.redactHeaders, _ = cmd.Flags().GetStringSlice("redact-header")
+	opts.history, _ = cmd.Flags().GetInt("history")
+
+	noRedact, _ := cmd.Flags().GetBool("no-redact")
+	opts.redact = !noRedact
 
-	http.HandleFunc("/", proxy)
+	opts.readOnly, _ = cmd.Flags().GetBool("read-only")
+	opts.allow, _ = cmd.Flags().GetStringArray("allow")
+	if len(opts.allow) > 0 && !opts.readOnly {
+		return opts, fmt.Errorf("--allow requires --read-only")
+	}
+	for _, s := range opts.allow {
+		if _, err := parseProxyAllow(s); err != nil {
+			return opts, err
+		}
+	}
 
-	fmt.Printf("Proxy server started at: http://localhost:%s\n", port)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+	opts.faultsFile, _ = cmd.Flags().GetString("faults")
+	opts.fault.Path, _ = cmd.Flags().GetString("fault-path")
+	opts.fault.Latency, _ = cmd.Flags().GetDuration("latency")
+	opts.fault.Jitter, _ = cmd.Flags().GetDuration("jitter")
+	opts.fault.ErrorRate, _ = cmd.Flags().GetFloat64("error-rate")
+	opts.fault.ErrorStatus, _ = cmd.Flags().GetInt("error-status")
+	opts.fault.DropRate, _ = cmd.Flags().GetFloat64("drop-rate")
+	opts.fault.RateLimit, _ = cmd.Flags().GetInt("rate-limit")
+	if err := opts.fault.validate(); err != nil {
+		return opts, fmt.Errorf("invalid fault flags: %w", err)
+	}
+
+	return opts, nil
 }
 
-func proxy(w http.ResponseWriter, r *http.Request) {
-	t, err := url.Parse(proxyTarget)
+func startProxy(opts proxyOptions) {
+	pl, err := newProxyLogger(opts)
 	if err != nil {
-		log.Fatal("invalid proxy target: ", err)
+		printError("unable to open the log file: %v", err)
+		os.Exit(1)
 	}
 
-	proxy := httputil.NewSingleHostReverseProxy(t)
+	// a single transport keeps the connections to the targets alive
+	transport := newProxyTransport()
+	relay := func(target *url.URL) http.Handler {
+		return newProxyHandler(target, transport, len(opts.corsOrigins) > 0)
+	}
 
-	// Update the headers to allow for SSL redirection
-	r.URL.Host = t.Host
-	r.URL.Scheme = t.Scheme
-	r.Header.Set("X-Forwarded-Host", r.Header.Get("Host"))
-	r.Host = t.Host
+	var upstream http.Handler
+	if len(opts.rulesFile) > 0 {
+		router, err := loadProxyRules(opts.rulesFile, opts.target, relay)
+		if err != nil {
+			printError("invalid rules file %s: %v", opts.rulesFile, err)
+			os.Exit(1)
+		}
+		upstream = router
+		fmt.Printf("Routing requests with %s:\n%s", opts.rulesFile, router.summary())
+		printProxyTargetWarning(router.targets(), opts.readOnly)
+	} else if opts.target != nil {
+		upstream = relay(opts.target)
+		printProxyTargetWarning(map[string]*url.URL{"region": opts.target}, opts.readOnly)
+	}
+
+	var h http.Handler
+	var hr *proxyHARRecorder
+	switch {
+	case len(opts.replay) > 0:
+		pr, n, err := loadProxyReplayer(opts.replay)
+		if err != nil {
+			printError("unable to load %s: %v", opts.replay, err)
+			os.Exit(1)
+		}
+		h = pr
+		fmt.Printf("Replaying %d recorded exchange(s) from %s\n", n, opts.replay)
+		if pr.skipped > 0 {
+			printWarning("%d exchange(s) with truncated bodies are not replayed, record them with a higher --max-body-size", pr.skipped)
+		}
+	case len(opts.record) > 0:
+		hr, err = newProxyHARRecorder(opts.record, opts.maxBodySize, pl.redactHeaders, opts.redact)
+		if err != nil {
+			printError("unable to create %s: %v", opts.record, err)
+			os.Exit(1)
+		}
+		h = hr.middleware(upstream)
+		fmt.Printf("Recording exchanges to %s\n", opts.record)
+	default:
+		h = upstream
+	}
+
+	var faults []proxyFault
+	if len(opts.faultsFile) > 0 {
+		faults, err = loadProxyFaults(opts.faultsFile)
+		if err != nil {
+			printError("invalid faults file %s: %v", opts.faultsFile, err)
+			os.Exit(1)
+		}
+	}
+	if opts.fault.enabled() {
+		faults = append(faults, opts.fault)
+	}
+
+	var pf *proxyFaulter
+	if len(faults) > 0 {
+		pf, err = newProxyFaulter(faults)
+		if err != nil {
+			printError("invalid faults: %v", err)
+			os.Exit(1)
+		}
+		h = pf.middleware(h)
+		fmt.Printf("Injecting faults:\n%s", pf.summary())
+	}
+
+	if opts.readOnly {
+		ro, err := newProxyReadOnly(opts.allow)
+		if err != nil {
+			printError("%v", err)
+			os.Exit(1)
+		}
+		h = ro.middleware(h)
+		fmt.Println("Read-only mode: requests that may change data are rejected")
+	}
+
+	if len(opts.corsOrigins) > 0 {
+		h = devCORS(opts.corsOrigins, h)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/_proxy/", pl.inspectorHandler())
+	mux.Handle("/", pl.middleware(h))
+
+	srv := &http.Server{
+		Addr:              net.JoinHostPort(opts.host, opts.port),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	scheme := "http"
+	if opts.tls {
+		cert, fingerprint, err := proxySelfSignedCert(opts.host)
+		if err != nil {
+			printError("unable to generate the TLS certificate: %v", err)
+			os.Exit(1)
+		}
+		srv.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		scheme = "https"
+		fmt.Printf("Serving HTTPS with a self-signed certificate, SHA-256 fingerprint:\n  %s\n", fingerprint)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		<-sig
+
+		fmt.Println("\nShutting down the proxy...")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			srv.Close()
+		}
+	}()
+
+	base := fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(proxyDisplayHost(opts.host), opts.port))
+	fmt.Printf("Proxy server started at: %s\n", base)
+	fmt.Printf("Inspect requests at: %s/_proxy/\n", base)
+
+	if opts.tls {
+		err = srv.ListenAndServeTLS("", "")
+	} else {
+		err = srv.ListenAndServe()
+	}
+	if !errors.Is(err, http.ErrServerClosed) {
+		printError("unable to start the proxy: %v", err)
+		os.Exit(1)
+	}
+
+	<-done
+	if hr != nil {
+		if err := hr.Close(); err != nil {
+			printError("unable to write %s: %v", opts.record, err)
+		}
+	}
+	if pf != nil {
+		pf.printCounts()
+	}
+	if err := pl.Close(); err != nil {
+		printError("unable to close the log file: %v", err)
+	}
+}
+
+// proxyDisplayHost is the host printed in the proxy URLs.
+func proxyDisplayHost(host string) string {
+	if ip := net.ParseIP(host); len(host) == 0 || (ip != nil && ip.IsUnspecified()) {
+		return "localhost"
+	}
+	return host
+}
+
+func newProxyTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   32,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// newProxyHandler relays requests to target, replaceCORS removes the CORS
+// headers of the target when the proxy sets its own.
+func newProxyHandler(target *url.URL, transport http.RoundTripper, replaceCORS bool) http.Handler {
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.Transport = transport
+
+	if replaceCORS {
+		proxy.ModifyResponse = devStripCORS
+	}
+
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		host := r.Host
+		director(r)
+
+		// Update the headers to allow for SSL redirection
+		r.Header.Set("X-Forwarded-Host", host)
+		r.Host = target.Host
+	}
 
-	// Note that ServeHttp is non blocking and uses a go routine under the hood
-	proxy.ServeHTTP(w, r)
+	return proxy
 }
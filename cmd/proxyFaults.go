@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// proxyFaultHeader is added to the injected responses with the kind of fault.
+const proxyFaultHeader = "X-Proxy-Fault"
+
+// proxyErrorStatuses are picked at random when a fault has no errorStatus.
+var proxyErrorStatuses = []int{
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// proxyFaultsFile is the YAML file given to --faults, the first fault
+// matching a request applies:
+//
+//	faults:
+//	  - path: /db/*
+//	    methods: [GET]
+//	    latency: 200ms
+//	    jitter: 300ms
+//	    errorRate: 0.1
+//	    errorStatus: 503
+//	  - path: /fn/*
+//	    dropRate: 0.05
+//	    rateLimit: 5
+type proxyFaultsFile struct {
+	Faults []proxyFault `yaml:"faults"`
+}
+
+type proxyFault struct {
+	Path    string   `yaml:"path"`
+	Methods []string `yaml:"methods"`
+	// Latency is added to every matching request, plus a random duration
+	// up to Jitter.
+	Latency time.Duration `yaml:"latency"`
+	Jitter  time.Duration `yaml:"jitter"`
+	// ErrorRate and DropRate are probabilities between 0 and 1.
+	ErrorRate   float64 `yaml:"errorRate"`
+	ErrorStatus int     `yaml:"errorStatus"`
+	DropRate    float64 `yaml:"dropRate"`
+	// RateLimit is the number of requests per second allowed before
+	// responding with 429, 0 disables it.
+	RateLimit int `yaml:"rateLimit"`
+}
+
+func (f proxyFault) validate() error {
+	if len(f.Path) == 0 {
+		return fmt.Errorf("no path")
+	}
+	if f.Latency < 0 || f.Jitter < 0 {
+		return fmt.Errorf("latency and jitter cannot be negative")
+	}
+	if f.ErrorRate < 0 || f.ErrorRate > 1 || f.DropRate < 0 || f.DropRate > 1 {
+		return fmt.Errorf("errorRate and dropRate must be between 0 and 1")
+	}
+	if f.ErrorStatus != 0 && (f.ErrorStatus < 500 || f.ErrorStatus > 599) {
+		return fmt.Errorf("errorStatus %d is not a 5xx status", f.ErrorStatus)
+	}
+	if f.RateLimit < 0 {
+		return fmt.Errorf("rateLimit cannot be negative")
+	}
+	return nil
+}
+
+func (f proxyFault) enabled() bool {
+	return f.Latency > 0 || f.Jitter > 0 || f.ErrorRate > 0 || f.DropRate > 0 || f.RateLimit > 0
+}
+
+// proxyFaultCounts are the requests a fault matched and what was injected.
+type proxyFaultCounts struct {
+	requests    int
+	delayed     int
+	errors      int
+	dropped     int
+	rateLimited int
+}
+
+// proxyFaultState is the rate limit window and counters of a fault.
+type proxyFaultState struct {
+	fault proxyFault
+
+	mu          sync.Mutex
+	windowStart time.Time
+	windowCount int
+	counts      proxyFaultCounts
+}
+
+// proxyFaulter injects latency, errors, dropped connections and rate limits
+// before relaying the requests.
+type proxyFaulter struct {
+	faults []*proxyFaultState
+	// random returns a number in [0, 1), replaced in tests.
+	random func() float64
+	now    func() time.Time
+}
+
+func newProxyFaulter(faults []proxyFault) (*proxyFaulter, error) {
+	pf := &proxyFaulter{random: rand.Float64, now: time.Now}
+
+	for i, f := range faults {
+		if err := f.validate(); err != nil {
+			return nil, fmt.Errorf("fault #%d: %w", i+1, err)
+		}
+		pf.faults = append(pf.faults, &proxyFaultState{fault: f})
+	}
+
+	return pf, nil
+}
+
+// loadProxyFaults reads the faults file.
+func loadProxyFaults(path string) ([]proxyFault, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file proxyFaultsFile
+	if err := yaml.UnmarshalStrict(b, &file); err != nil {
+		return nil, err
+	}
+
+	return file.Faults, nil
+}
+
+// match returns the state of the first fault matching the request.
+func (pf *proxyFaulter) match(r *http.Request) *proxyFaultState {
+	for _, fs := range pf.faults {
+		if proxyMatch(fs.fault.Path, fs.fault.Methods, r.Method, r.URL.Path) {
+			return fs
+		}
+	}
+	return nil
+}
+
+func (pf *proxyFaulter) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fs := pf.match(r)
+		if fs == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		f := fs.fault
+		fs.mu.Lock()
+		fs.counts.requests++
+
+		limited := false
+		if f.RateLimit > 0 {
+			now := pf.now()
+			if now.Sub(fs.windowStart) >= time.Second {
+				fs.windowStart, fs.windowCount = now, 0
+			}
+			fs.windowCount++
+			if fs.windowCount > f.RateLimit {
+				limited = true
+				fs.counts.rateLimited++
+			}
+		}
+		fs.mu.Unlock()
+
+		if limited {
+			w.Header().Set(proxyFaultHeader, "rate-limit")
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "rate limit exceeded (injected by backend proxy)", http.StatusTooManyRequests)
+			return
+		}
+
+		if delay := f.Latency + time.Duration(pf.random()*float64(f.Jitter)); delay > 0 {
+			fs.count(func(c *proxyFaultCounts) { c.delayed++ })
+
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if f.DropRate > 0 && pf.random() < f.DropRate {
+			fs.count(func(c *proxyFaultCounts) { c.dropped++ })
+			proxyDropConnection(w)
+			return
+		}
+
+		if f.ErrorRate > 0 && pf.random() < f.ErrorRate {
+			fs.count(func(c *proxyFaultCounts) { c.errors++ })
+
+			status := f.ErrorStatus
+			if status == 0 {
+				status = proxyErrorStatuses[int(pf.random()*float64(len(proxyErrorStatuses)))]
+			}
+
+			w.Header().Set(proxyFaultHeader, "error")
+			http.Error(w, fmt.Sprintf("%s (injected by backend proxy)", http.StatusText(status)), status)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (fs *proxyFaultState) count(fn func(*proxyFaultCounts)) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fn(&fs.counts)
+}
+
+func (fs *proxyFaultState) snapshot() proxyFaultCounts {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.counts
+}
+
+// proxyDropConnection closes the client connection without a response.
+func proxyDropConnection(w http.ResponseWriter) {
+	conn, _, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		// the server closes the connection when a handler panics with it
+		panic(http.ErrAbortHandler)
+	}
+	conn.Close()
+}
+
+// summary describes the faults, printed when the proxy starts.
+func (pf *proxyFaulter) summary() string {
+	var sb strings.Builder
+
+	for _, fs := range pf.faults {
+		f := fs.fault
+
+		var parts []string
+		if f.Latency > 0 || f.Jitter > 0 {
+			parts = append(parts, fmt.Sprintf("latency %v+%v", f.Latency, f.Jitter))
+		}
+		if f.ErrorRate > 0 {
+			status := "5xx"
+			if f.ErrorStatus > 0 {
+				status = fmt.Sprint(f.ErrorStatus)
+			}
+			parts = append(parts, fmt.Sprintf("%s %.0f%%", status, f.ErrorRate*100))
+		}
+		if f.DropRate > 0 {
+			parts = append(parts, fmt.Sprintf("drop %.0f%%", f.DropRate*100))
+		}
+		if f.RateLimit > 0 {
+			parts = append(parts, fmt.Sprintf("429 above %d req/s", f.RateLimit))
+		}
+
+		methods := "*"
+		if len(f.Methods) > 0 {
+			methods = strings.ToUpper(strings.Join(f.Methods, ","))
+		}
+		fmt.Fprintf(&sb, "  %s %s: %s\n", methods, f.Path, strings.Join(parts, ", "))
+	}
+
+	return sb.String()
+}
+
+// printCounts prints the injected faults, called when the proxy exits.
+func (pf *proxyFaulter) printCounts() {
+	fmt.Printf("\n%s\n", clbold("Injected faults"))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tMETHODS\tREQUESTS\tDELAYED\tERRORS\tDROPPED\tRATE LIMITED")
+
+	for _, fs := range pf.faults {
+		c := fs.snapshot()
+
+		methods := "*"
+		if len(fs.fault.Methods) > 0 {
+			methods = strings.ToUpper(strings.Join(fs.fault.Methods, ","))
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%d\n",
+			fs.fault.Path, methods, c.requests, c.delayed, c.errors, c.dropped, c.rateLimited)
+	}
+
+	w.Flush()
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProxyFaulter(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+
+	pf, err := newProxyFaulter([]proxyFault{
+		{Path: "/db/*", Methods: []string{"GET"}, ErrorRate: 0.5, ErrorStatus: 503},
+		{Path: "/fn/*", RateLimit: 2},
+	})
+	if err != nil {
+		t.Fatalf("newProxyFaulter returned error: %v", err)
+	}
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	pf.now = func() time.Time { return now }
+
+	random := 0.4
+	pf.random = func() float64 { return random }
+
+	h := pf.middleware(next)
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	if rec := serve(http.MethodGet, "/db/tasks"); rec.Code != http.StatusServiceUnavailable || rec.Header().Get(proxyFaultHeader) != "error" {
+		t.Fatalf("GET /db/tasks = %d %q, want an injected 503", rec.Code, rec.Header().Get(proxyFaultHeader))
+	}
+
+	random = 0.6
+	if rec := serve(http.MethodGet, "/db/tasks"); rec.Code != http.StatusOK {
+		t.Fatalf("GET /db/tasks = %d, want it relayed", rec.Code)
+	}
+
+	random = 0
+	if rec := serve(http.MethodPost, "/db/tasks"); rec.Code != http.StatusOK {
+		t.Fatalf("POST /db/tasks = %d, want it relayed", rec.Code)
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := serve(http.MethodGet, "/fn/exec"); rec.Code != http.StatusOK {
+			t.Fatalf("request #%d to /fn/exec = %d, want it relayed", i+1, rec.Code)
+		}
+	}
+	if rec := serve(http.MethodGet, "/fn/exec"); rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "1" {
+		t.Fatalf("third request to /fn/exec = %d, want 429 with Retry-After", rec.Code)
+	}
+
+	now = now.Add(time.Second)
+	if rec := serve(http.MethodGet, "/fn/exec"); rec.Code != http.StatusOK {
+		t.Fatalf("request to /fn/exec in the next second = %d, want it relayed", rec.Code)
+	}
+
+	db, fn := pf.faults[0].snapshot(), pf.faults[1].snapshot()
+	if db.requests != 2 || db.errors != 1 {
+		t.Fatalf("/db/* counts = %+v, want 2 requests and 1 error", db)
+	}
+	if fn.requests != 4 || fn.rateLimited != 1 {
+		t.Fatalf("/fn/* counts = %+v, want 4 requests and 1 rate limited", fn)
+	}
+}
+
+func TestProxyFaulterDrop(t *testing.T) {
+	pf, err := newProxyFaulter([]proxyFault{{Path: "*", DropRate: 1}})
+	if err != nil {
+		t.Fatalf("newProxyFaulter returned error: %v", err)
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("dropped request was relayed")
+	})
+
+	srv := httptest.NewServer(pf.middleware(next))
+	defer srv.Close()
+
+	if resp, err := http.Get(srv.URL + "/db/tasks"); err == nil {
+		resp.Body.Close()
+		t.Fatalf("got status %d, want the connection closed", resp.StatusCode)
+	}
+
+	if n := pf.faults[0].snapshot().dropped; n != 1 {
+		t.Fatalf("dropped = %d, want 1", n)
+	}
+}
+
+func TestProxyFaultValidate(t *testing.T) {
+	invalid := []proxyFault{
+		{},
+		{Path: "*", ErrorRate: 1.5},
+		{Path: "*", DropRate: -0.1},
+		{Path: "*", ErrorStatus: 404},
+		{Path: "*", Latency: -time.Second},
+		{Path: "*", RateLimit: -1},
+	}
+
+	for _, f := range invalid {
+		if err := f.validate(); err == nil {
+			t.Fatalf("validate(%+v) returned no error", f)
+		}
+	}
+
+	if err := (proxyFault{Path: "/db/*", ErrorRate: 1, ErrorStatus: 502}).validate(); err != nil {
+		t.Fatalf("validate returned error: %v", err)
+	}
+}
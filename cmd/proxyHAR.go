@@ -0,0 +1,531 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// HAR 1.2 types, only the fields the proxy records and replays.
+type harFile struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+	// Truncated is set when a body was cut to --max-body-size, the entry
+	// cannot be replayed.
+	Truncated bool `json:"_truncated,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// harSkippedHeaders are not recorded since the replayed body length is
+// computed again. Content-Encoding is only removed once the body is decoded.
+var harSkippedHeaders = map[string]bool{
+	"Content-Length":    true,
+	"Transfer-Encoding": true,
+	"Connection":        true,
+}
+
+// proxyHARFlushInterval is how often the recorded exchanges are written to
+// the HAR file, they are also written when the proxy stops.
+const proxyHARFlushInterval = 2 * time.Second
+
+// proxyHARSecretPaths exchange credentials and tokens in their bodies, the
+// bodies are redacted unless --no-redact is used and replayed requests are
+// matched without them.
+var proxyHARSecretPaths = []string{"/login", "/register", "/password/*", "/sudogettoken/*"}
+
+func proxyHARSecretPath(path string) bool {
+	for _, p := range proxyHARSecretPaths {
+		if proxyMatch(p, nil, "", path) {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyHARRecorder saves every exchange to a HAR file. The entries are
+// appended periodically before the closing brackets of the file, which is
+// a valid HAR file between writes.
+type proxyHARRecorder struct {
+	path        string
+	maxBodySize int
+	redact      func(http.Header) http.Header
+	// redactBodies hides the bodies of the proxyHARSecretPaths.
+	redactBodies bool
+
+	mu      sync.Mutex
+	f       *os.File
+	tail    []byte
+	offset  int64
+	written int
+	pending []harEntry
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newProxyHARRecorder(path string, maxBodySize int, redact func(http.Header) http.Header, redactBodies bool) (*proxyHARRecorder, error) {
+	b, err := json.MarshalIndent(harFile{Log: harLog{
+		Version: "1.2",
+		Creator: harCreator{Name: "StaticBackend CLI", Version: Version},
+		Entries: []harEntry{},
+	}}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	// the entries go between the brackets of the empty array
+	i := bytes.LastIndex(b, []byte("[]")) + 1
+	hr := &proxyHARRecorder{
+		path:         path,
+		maxBodySize:  maxBodySize,
+		redact:       redact,
+		redactBodies: redactBodies,
+		f:            f,
+		tail:         append(append([]byte{}, b[i:]...), '\n'),
+		offset:       int64(i),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+
+	if _, err := f.Write(append(b[:i:i], hr.tail...)); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	go hr.flushLoop()
+
+	return hr, nil
+}
+
+func (hr *proxyHARRecorder) flushLoop() {
+	defer close(hr.done)
+
+	ticker := time.NewTicker(proxyHARFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := hr.flush(); err != nil {
+				printError("unable to write %s: %v", hr.path, err)
+			}
+		case <-hr.stop:
+			return
+		}
+	}
+}
+
+// Close writes the pending entries and closes the file.
+func (hr *proxyHARRecorder) Close() error {
+	close(hr.stop)
+	<-hr.done
+
+	err := hr.flush()
+
+	hr.mu.Lock()
+	defer hr.mu.Unlock()
+
+	if cerr := hr.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (hr *proxyHARRecorder) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		// the headers are copied before the proxy changes them
+		reqHeaders := hr.redact(r.Header)
+
+		reqBody := &proxyCountingReader{r: r.Body, capture: &cappedBuffer{limit: hr.maxBodySize}}
+		if r.Body != nil {
+			r.Body = reqBody
+		}
+
+		rec := &proxyRecorder{
+			devStatusRecorder: devStatusRecorder{ResponseWriter: w},
+			capture:           &cappedBuffer{limit: hr.maxBodySize},
+		}
+
+		next.ServeHTTP(rec, r)
+
+		reqText, respText := reqBody.capture.buf.Bytes(), rec.capture.buf.Bytes()
+
+		var comments []string
+		if reqBody.capture.truncated {
+			comments = append(comments, fmt.Sprintf("request body truncated to %d bytes", hr.maxBodySize))
+		}
+		if rec.capture.truncated {
+			comments = append(comments, fmt.Sprintf("response body truncated to %d bytes", hr.maxBodySize))
+		}
+
+		if hr.redactBodies && proxyHARSecretPath(r.URL.Path) {
+			redacted := []byte(proxyRedacted)
+			if len(reqText) > 0 {
+				reqText = redacted
+			}
+			respText = redacted
+			comments = append(comments, "bodies redacted")
+		}
+
+		elapsed := float64(time.Since(start).Microseconds()) / 1000
+		entry := harEntry{
+			StartedDateTime: start,
+			Time:            elapsed,
+			Request:         harNewRequest(r, reqHeaders, reqText),
+			Response:        harNewResponse(rec.status, hr.redact(w.Header()), respText),
+			Timings:         harTimings{Wait: elapsed},
+			Comment:         strings.Join(comments, ", "),
+			Truncated:       reqBody.capture.truncated || rec.capture.truncated,
+		}
+		entry.Request.BodySize = int(reqBody.n)
+
+		hr.add(entry)
+	})
+}
+
+// add queues the entry for the next flush.
+func (hr *proxyHARRecorder) add(entry harEntry) {
+	hr.mu.Lock()
+	defer hr.mu.Unlock()
+
+	hr.pending = append(hr.pending, entry)
+}
+
+// flush appends the pending entries to the file, followed by the closing
+// brackets.
+func (hr *proxyHARRecorder) flush() error {
+	hr.mu.Lock()
+	defer hr.mu.Unlock()
+
+	if len(hr.pending) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, e := range hr.pending {
+		b, err := json.MarshalIndent(e, "      ", "  ")
+		if err != nil {
+			return err
+		}
+
+		if hr.written > 0 || buf.Len() > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n      ")
+		buf.Write(b)
+	}
+	buf.WriteString("\n    ")
+
+	// the closing brackets written last time are overwritten
+	if _, err := hr.f.WriteAt(append(buf.Bytes(), hr.tail...), hr.offset); err != nil {
+		return err
+	}
+
+	// the next entries go before the indentation of the closing bracket
+	hr.offset += int64(buf.Len() - len("\n    "))
+	hr.written += len(hr.pending)
+	hr.pending = nil
+	return nil
+}
+
+func harNewRequest(r *http.Request, h http.Header, body []byte) harRequest {
+	u := *r.URL
+	u.Scheme, u.Host = "http", r.Host
+	if r.TLS != nil {
+		u.Scheme = "https"
+	}
+
+	req := harRequest{
+		Method:      r.Method,
+		URL:         u.String(),
+		HTTPVersion: r.Proto,
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(h),
+		QueryString: []harNameValue{},
+		HeadersSize: -1,
+		BodySize:    len(body),
+	}
+
+	for k, values := range r.URL.Query() {
+		for _, v := range values {
+			req.QueryString = append(req.QueryString, harNameValue{Name: k, Value: v})
+		}
+	}
+
+	if len(body) > 0 {
+		req.PostData = &harPostData{MimeType: r.Header.Get("Content-Type"), Text: string(body)}
+	}
+
+	return req
+}
+
+func harNewResponse(status int, h http.Header, body []byte) harResponse {
+	// a body that fails to decode, truncated for instance, is kept encoded
+	// along with its Content-Encoding
+	if strings.EqualFold(h.Get("Content-Encoding"), "gzip") {
+		if zr, err := gzip.NewReader(bytes.NewReader(body)); err == nil {
+			if plain, err := io.ReadAll(zr); err == nil {
+				body = plain
+				h = h.Clone()
+				h.Del("Content-Encoding")
+			}
+		}
+	}
+
+	resp := harResponse{
+		Status:      status,
+		StatusText:  http.StatusText(status),
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(h),
+		HeadersSize: -1,
+		BodySize:    len(body),
+		Content: harContent{
+			Size:     len(body),
+			MimeType: h.Get("Content-Type"),
+		},
+	}
+
+	if utf8.Valid(body) {
+		resp.Content.Text = string(body)
+	} else {
+		resp.Content.Text = base64.StdEncoding.EncodeToString(body)
+		resp.Content.Encoding = "base64"
+	}
+
+	return resp
+}
+
+func harHeaders(h http.Header) []harNameValue {
+	headers := []harNameValue{}
+	for k, values := range h {
+		if harSkippedHeaders[http.CanonicalHeaderKey(k)] {
+			continue
+		}
+		for _, v := range values {
+			headers = append(headers, harNameValue{Name: k, Value: v})
+		}
+	}
+
+	sort.Slice(headers, func(i, j int) bool { return headers[i].Name < headers[j].Name })
+	return headers
+}
+
+// proxyReplayer serves the responses of a HAR file.
+type proxyReplayer struct {
+	mu      sync.Mutex
+	entries map[string][]harEntry
+	// skipped counts the truncated entries, their bodies are incomplete
+	skipped int
+	// served counts the responses served per key, identical requests get
+	// the recorded responses in order, then the last one again.
+	served map[string]int
+}
+
+func loadProxyReplayer(path string) (*proxyReplayer, int, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var file harFile
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, 0, fmt.Errorf("invalid HAR file: %w", err)
+	}
+
+	pr := &proxyReplayer{entries: map[string][]harEntry{}, served: map[string]int{}}
+	n := 0
+	for _, e := range file.Log.Entries {
+		if e.Truncated {
+			pr.skipped++
+			continue
+		}
+
+		u, err := url.Parse(e.Request.URL)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid request URL %q: %w", e.Request.URL, err)
+		}
+
+		var body []byte
+		if e.Request.PostData != nil {
+			body = []byte(e.Request.PostData.Text)
+		}
+
+		key := proxyReplayKey(e.Request.Method, u.Path, u.Query(), body)
+		pr.entries[key] = append(pr.entries[key], e)
+		n++
+	}
+
+	return pr, n, nil
+}
+
+// proxyReplayKey identifies a request by method, path, query and body, the
+// query parameters are sorted and JSON bodies compacted. The bodies of the
+// proxyHARSecretPaths are ignored since they may be redacted.
+func proxyReplayKey(method, path string, query url.Values, body []byte) string {
+	if proxyHARSecretPath(path) {
+		body = nil
+	}
+
+	if v := []byte(strings.TrimSpace(string(body))); json.Valid(v) {
+		var doc any
+		if err := json.Unmarshal(v, &doc); err == nil {
+			// json.Marshal sorts map keys
+			if b, err := json.Marshal(doc); err == nil {
+				body = b
+			}
+		}
+	}
+
+	return strings.Join([]string{strings.ToUpper(method), path, query.Encode(), string(body)}, "\n")
+}
+
+func (pr *proxyReplayer) next(r *http.Request, body []byte) (harEntry, bool) {
+	key := proxyReplayKey(r.Method, r.URL.Path, r.URL.Query(), body)
+
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	entries := pr.entries[key]
+	if len(entries) == 0 {
+		return harEntry{}, false
+	}
+
+	i := pr.served[key]
+	if i >= len(entries) {
+		i = len(entries) - 1
+	}
+	pr.served[key]++
+
+	return entries[i], true
+}
+
+func (pr *proxyReplayer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body []byte
+	if r.Body != nil {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		body = b
+	}
+
+	e, ok := pr.next(r, body)
+	if !ok {
+		printWarning("no recorded response for %s %s", r.Method, r.URL.RequestURI())
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Proxy-Replay", "miss")
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": fmt.Sprintf("no recorded response for %s %s", r.Method, r.URL.RequestURI()),
+		})
+		return
+	}
+
+	content := []byte(e.Response.Content.Text)
+	if e.Response.Content.Encoding == "base64" {
+		b, err := base64.StdEncoding.DecodeString(e.Response.Content.Text)
+		if err != nil {
+			http.Error(w, "invalid recorded body: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		content = b
+	}
+
+	for _, h := range e.Response.Headers {
+		if harSkippedHeaders[http.CanonicalHeaderKey(h.Name)] {
+			continue
+		}
+		w.Header().Add(h.Name, h.Value)
+	}
+	w.Header().Set("X-Proxy-Replay", "hit")
+
+	w.WriteHeader(e.Response.Status)
+	w.Write(content)
+}
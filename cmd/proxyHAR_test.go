@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProxyRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.har")
+
+	calls := 0
+	backendSrv := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		switch {
+		case r.Method == http.MethodPost:
+			w.Write([]byte(`{"created":` + string(b) + `}`))
+		case calls == 1:
+			w.Write([]byte(`{"page":1}`))
+		default:
+			w.Write([]byte(`{"page":2}`))
+		}
+	})
+
+	pl, err := newProxyLogger(proxyOptions{redact: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hr, err := newProxyHARRecorder(path, 64, pl.redactHeaders, true)
+	if err != nil {
+		t.Fatalf("newProxyHARRecorder returned error: %v", err)
+	}
+	h := hr.middleware(backendSrv)
+
+	send := func(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	send(h, http.MethodGet, "/db/tasks?size=5&page=1", "")
+	send(h, http.MethodGet, "/db/tasks?size=5&page=1", "")
+	// flushed in two writes, the file must stay valid
+	if err := hr.flush(); err != nil {
+		t.Fatalf("flush returned error: %v", err)
+	}
+	if _, n, err := loadProxyReplayer(path); err != nil || n != 2 {
+		t.Fatalf("loadProxyReplayer after a flush = %d, %v, want 2 entries", n, err)
+	}
+
+	send(h, http.MethodPost, "/db/tasks", `{"title":"a","done":false}`)
+	send(h, http.MethodPost, "/login", `{"email":"a@b.com","password":"secret"}`)
+	send(h, http.MethodPost, "/db/notes", strings.Repeat("x", 100))
+
+	if err := hr.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	pr, n, err := loadProxyReplayer(path)
+	if err != nil {
+		t.Fatalf("loadProxyReplayer returned error: %v", err)
+	}
+	if n != 4 || pr.skipped != 1 {
+		t.Fatalf("loaded %d entries and skipped %d, want 4 and the truncated one skipped", n, pr.skipped)
+	}
+
+	login := pr.entries[proxyReplayKey(http.MethodPost, "/login", nil, nil)]
+	if len(login) != 1 || login[0].Request.PostData.Text != proxyRedacted || login[0].Response.Content.Text != proxyRedacted {
+		t.Fatalf("login recorded as %+v, want redacted bodies", login)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file harFile
+	if err := json.Unmarshal(b, &file); err != nil {
+		t.Fatal(err)
+	}
+	notes := file.Log.Entries[4]
+	if len(notes.Request.PostData.Text) != 64 || notes.Request.BodySize != 100 || !notes.Truncated || !strings.Contains(notes.Comment, "truncated") {
+		t.Fatalf("large body recorded as %d bytes of %d, truncated %v, comment %q", len(notes.Request.PostData.Text), notes.Request.BodySize, notes.Truncated, notes.Comment)
+	}
+
+	// the query order and JSON key order do not matter
+	tests := []struct {
+		method, target, body, want string
+	}{
+		{http.MethodGet, "/db/tasks?page=1&size=5", "", `{"page":1}`},
+		{http.MethodGet, "/db/tasks?page=1&size=5", "", `{"page":2}`},
+		{http.MethodGet, "/db/tasks?page=1&size=5", "", `{"page":2}`},
+		{http.MethodPost, "/db/tasks", `{"done": false, "title": "a"}`, `{"created":{"title":"a","done":false}}`},
+	}
+
+	for _, tt := range tests {
+		rec := send(pr, tt.method, tt.target, tt.body)
+		if rec.Code != http.StatusOK || rec.Body.String() != tt.want {
+			t.Fatalf("replay %s %s = %d %q, want %q", tt.method, tt.target, rec.Code, rec.Body.String(), tt.want)
+		}
+	}
+
+	for _, tt := range []struct{ method, target, body string }{
+		{http.MethodDelete, "/db/tasks/1", ""},
+		{http.MethodPost, "/db/notes", strings.Repeat("x", 100)},
+	} {
+		rec := send(pr, tt.method, tt.target, tt.body)
+		if rec.Code != http.StatusBadGateway || rec.Header().Get("X-Proxy-Replay") != "miss" {
+			t.Fatalf("unreplayable request %s %s returned %d", tt.method, tt.target, rec.Code)
+		}
+	}
+
+	for _, entries := range pr.entries {
+		for _, e := range entries {
+			for _, h := range e.Request.Headers {
+				if h.Name == "Authorization" && h.Value != proxyRedacted {
+					t.Fatalf("Authorization header recorded as %q", h.Value)
+				}
+			}
+		}
+	}
+}
+
+func TestHARNewResponseGzip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(`{"ok":true}`))
+	zw.Close()
+
+	h := http.Header{}
+	h.Set("Content-Encoding", "gzip")
+	h.Set("Content-Type", "application/json")
+
+	encoding := func(resp harResponse) string {
+		for _, nv := range resp.Headers {
+			if nv.Name == "Content-Encoding" {
+				return nv.Value
+			}
+		}
+		return ""
+	}
+
+	resp := harNewResponse(http.StatusOK, h, buf.Bytes())
+	if resp.Content.Text != `{"ok":true}` || encoding(resp) != "" {
+		t.Fatalf("decoded response = %q with encoding %q", resp.Content.Text, encoding(resp))
+	}
+
+	// a truncated body cannot be decoded, it is kept with its encoding
+	resp = harNewResponse(http.StatusOK, h, buf.Bytes()[:buf.Len()/2])
+	if resp.Content.Encoding != "base64" || encoding(resp) != "gzip" {
+		t.Fatalf("truncated response recorded as %q with encoding %q", resp.Content.Encoding, encoding(resp))
+	}
+	if h.Get("Content-Encoding") != "gzip" {
+		t.Fatal("harNewResponse changed the response headers")
+	}
+}
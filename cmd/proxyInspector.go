@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+var proxyInspectorTmpl = template.Must(template.New("inspector").Funcs(template.FuncMap{
+	"headers": func(h http.Header) string {
+		var sb strings.Builder
+		for k, v := range h {
+			sb.WriteString(k + ": " + strings.Join(v, ", ") + "\n")
+		}
+		return sb.String()
+	},
+}).Parse(`<!doctype html>
+<html>
+<head><meta charset="utf-8"><title>Proxy inspector</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+td, th { text-align: left; padding: .4em .8em; border-bottom: 1px solid #ddd; vertical-align: top; }
+pre { background: #f6f6f6; padding: .6em; white-space: pre-wrap; word-break: break-all; }
+.err { color: #c00; }
+</style>
+</head>
+<body>
+<h1>Proxy inspector</h1>
+<p>{{len .}} recent exchange(s) — <a href="/_proxy/">refresh</a> — <a href="/_proxy/exchanges.json">JSON</a></p>
+<table>
+<tr><th>#</th><th>TIME</th><th>REQUEST</th><th>TARGET</th><th>STATUS</th><th>LATENCY</th><th>SIZES</th></tr>
+{{range .}}<tr>
+<td>{{.ID}}</td>
+<td>{{.Time.Format "15:04:05"}}</td>
+<td><details><summary>{{.Method}} {{.Path}}{{if .Query}}?{{.Query}}{{end}}</summary>
+<h4>Request headers</h4><pre>{{headers .RequestHeaders}}</pre>
+{{if .RequestBody}}<h4>Request body</h4><pre>{{.RequestBody}}</pre>{{end}}
+<h4>Response headers</h4><pre>{{headers .ResponseHeaders}}</pre>
+{{if .ResponseBody}}<h4>Response body</h4><pre>{{.ResponseBody}}</pre>{{end}}
+</details></td>
+<td>{{.Target}}</td>
+<td{{if ge .Status 400}} class="err"{{end}}>{{.Status}}</td>
+<td>{{printf "%.1f" .DurationMs}}ms</td>
+<td>{{.RequestSize}}B / {{.ResponseSize}}B</td>
+</tr>{{end}}
+</table>
+</body>
+</html>`))
+
+// inspectorHandler serves the recent exchanges at /_proxy/ and as JSON at
+// /_proxy/exchanges.json.
+func (pl *proxyLogger) inspectorHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimPrefix(r.URL.Path, "/_proxy/") {
+		case "":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			if err := proxyInspectorTmpl.Execute(w, pl.recent()); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+		case "exchanges.json":
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(pl.recent())
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
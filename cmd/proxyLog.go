@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// proxyRedactedHeaders are hidden from the logs unless --no-redact is used.
+var proxyRedactedHeaders = []string{"SB-PUBLIC-KEY", "Authorization", "Cookie", "Set-Cookie"}
+
+const proxyRedacted = "[redacted]"
+
+// proxyExchange is a logged request and its response.
+type proxyExchange struct {
+	ID              int64       `json:"id"`
+	Time            time.Time   `json:"time"`
+	Method          string      `json:"method"`
+	Path            string      `json:"path"`
+	Query           string      `json:"query,omitempty"`
+	Target          string      `json:"target,omitempty"`
+	Status          int         `json:"status"`
+	DurationMs      float64     `json:"durationMs"`
+	RequestSize     int64       `json:"requestSize"`
+	ResponseSize    int64       `json:"responseSize"`
+	RequestHeaders  http.Header `json:"requestHeaders,omitempty"`
+	ResponseHeaders http.Header `json:"responseHeaders,omitempty"`
+	RequestBody     string      `json:"requestBody,omitempty"`
+	ResponseBody    string      `json:"responseBody,omitempty"`
+}
+
+// proxyLogger logs the exchanges to the console and the log file, and keeps
+// the recent ones for the inspector page.
+type proxyLogger struct {
+	opts   proxyOptions
+	redact map[string]bool
+
+	mu      sync.Mutex
+	file    *os.File
+	nextID  int64
+	history []proxyExchange
+}
+
+func newProxyLogger(opts proxyOptions) (*proxyLogger, error) {
+	pl := &proxyLogger{opts: opts, redact: map[string]bool{}}
+
+	if opts.redact {
+		for _, h := range append(proxyRedactedHeaders, opts.redactHeaders...) {
+			pl.redact[http.CanonicalHeaderKey(h)] = true
+		}
+	}
+
+	if len(opts.logFile) > 0 {
+		f, err := os.OpenFile(opts.logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, err
+		}
+		pl.file = f
+	}
+
+	return pl, nil
+}
+
+func (pl *proxyLogger) Close() error {
+	if pl.file == nil {
+		return nil
+	}
+	return pl.file.Close()
+}
+
+// middleware logs the exchanges handled by next.
+func (pl *proxyLogger) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		x := proxyExchange{
+			Time:           start,
+			Method:         r.Method,
+			Path:           r.URL.Path,
+			Query:          r.URL.RawQuery,
+			RequestHeaders: pl.redactHeaders(r.Header),
+		}
+
+		reqBody := &proxyCountingReader{r: r.Body}
+		if pl.opts.captureBodies {
+			reqBody.capture = &cappedBuffer{limit: pl.opts.maxBodySize}
+		}
+		if r.Body != nil {
+			r.Body = reqBody
+		}
+
+		rec := &proxyRecorder{devStatusRecorder: devStatusRecorder{ResponseWriter: w}}
+		if pl.opts.captureBodies {
+			rec.capture = &cappedBuffer{limit: pl.opts.maxBodySize}
+		}
+
+		next.ServeHTTP(rec, r)
+
+		x.Status = rec.status
+		x.Target = w.Header().Get(proxyTargetHeader)
+		x.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+		x.RequestSize = reqBody.n
+		x.ResponseSize = int64(rec.size)
+		x.ResponseHeaders = pl.redactHeaders(w.Header())
+
+		if pl.opts.captureBodies {
+			x.RequestBody = proxyBodyText(reqBody.capture, r.Header.Get("Content-Encoding"))
+			x.ResponseBody = proxyBodyText(rec.capture, w.Header().Get("Content-Encoding"))
+		}
+
+		pl.log(x)
+	})
+}
+
+func (pl *proxyLogger) log(x proxyExchange) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+
+	pl.nextID++
+	x.ID = pl.nextID
+
+	if pl.opts.history > 0 {
+		pl.history = append(pl.history, x)
+		if len(pl.history) > pl.opts.history {
+			pl.history = pl.history[len(pl.history)-pl.opts.history:]
+		}
+	}
+
+	if pl.file != nil {
+		b, err := json.Marshal(x)
+		if err == nil {
+			_, err = pl.file.Write(append(b, '\n'))
+		}
+		if err != nil {
+			printError("unable to write to the log file: %v", err)
+		}
+	}
+
+	if !pl.opts.noLog {
+		var target string
+		if len(x.Target) > 0 {
+			target = " -> " + x.Target
+		}
+
+		fmt.Printf("%s %s %s%s %d %.1fms %dB/%dB\n",
+			x.Time.Format("15:04:05"),
+			x.Method,
+			x.Path,
+			target,
+			x.Status,
+			x.DurationMs,
+			x.RequestSize,
+			x.ResponseSize,
+		)
+	}
+}
+
+// recent returns the kept exchanges, newest first.
+func (pl *proxyLogger) recent() []proxyExchange {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+
+	list := make([]proxyExchange, len(pl.history))
+	for i, x := range pl.history {
+		list[len(list)-1-i] = x
+	}
+	return list
+}
+
+func (pl *proxyLogger) redactHeaders(h http.Header) http.Header {
+	out := h.Clone()
+	for k := range out {
+		if pl.redact[http.CanonicalHeaderKey(k)] {
+			out[k] = []string{proxyRedacted}
+		}
+	}
+	return out
+}
+
+// proxyRecorder records the status, size and optionally the body of a
+// response.
+type proxyRecorder struct {
+	devStatusRecorder
+	capture *cappedBuffer
+}
+
+func (rec *proxyRecorder) Write(b []byte) (int, error) {
+	n, err := rec.devStatusRecorder.Write(b)
+	if rec.capture != nil {
+		rec.capture.Write(b[:n])
+	}
+	return n, err
+}
+
+// proxyCountingReader counts and optionally captures a request body as the
+// proxy reads it.
+type proxyCountingReader struct {
+	r       io.ReadCloser
+	n       int64
+	capture *cappedBuffer
+}
+
+func (cr *proxyCountingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	if cr.capture != nil {
+		cr.capture.Write(p[:n])
+	}
+	return n, err
+}
+
+func (cr *proxyCountingReader) Close() error {
+	return cr.r.Close()
+}
+
+// cappedBuffer keeps the first limit bytes written to it.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (cb *cappedBuffer) Write(p []byte) (int, error) {
+	if room := cb.limit - cb.buf.Len(); room < len(p) {
+		cb.truncated = true
+		if room > 0 {
+			cb.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return cb.buf.Write(p)
+}
+
+// proxyBodyText returns a loggable version of a captured body.
+func proxyBodyText(cb *cappedBuffer, encoding string) string {
+	if cb == nil || cb.buf.Len() == 0 {
+		return ""
+	}
+
+	b := cb.buf.Bytes()
+	if strings.EqualFold(encoding, "gzip") {
+		if cb.truncated {
+			return fmt.Sprintf("[gzip body truncated at %d bytes]", len(b))
+		}
+
+		zr, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return fmt.Sprintf("[invalid gzip body of %d bytes]", len(b))
+		}
+
+		plain, err := io.ReadAll(zr)
+		if err != nil {
+			return fmt.Sprintf("[invalid gzip body of %d bytes]", len(b))
+		}
+		b = plain
+	}
+
+	if !utf8.Valid(b) {
+		return fmt.Sprintf("[binary body of %d bytes]", len(b))
+	}
+
+	s := string(b)
+	if cb.truncated {
+		s += "…[truncated]"
+	}
+	return s
+}
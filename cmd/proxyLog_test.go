@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProxyLoggerMiddleware(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "proxy.ndjson")
+
+	pl, err := newProxyLogger(proxyOptions{
+		logFile:       logFile,
+		noLog:         true,
+		captureBodies: true,
+		maxBodySize:   1024,
+		redact:        true,
+		redactHeaders: []string{"X-Api-Key"},
+		history:       1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pl.Close()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"1"}`))
+	})
+	h := pl.middleware(next)
+
+	for _, path := range []string{"/db/tasks", "/db/notes"} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"title":"a"}`))
+		req.Header.Set("SB-PUBLIC-KEY", "secret-key")
+		req.Header.Set("X-Api-Key", "secret")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	recent := pl.recent()
+	if len(recent) != 1 {
+		t.Fatalf("recent kept %d exchanges, want 1", len(recent))
+	}
+
+	x := recent[0]
+	if x.ID != 2 || x.Path != "/db/notes" || x.Status != http.StatusCreated {
+		t.Fatalf("recent exchange = %d %s %d", x.ID, x.Path, x.Status)
+	}
+	if x.RequestSize != 13 || x.ResponseSize != 10 {
+		t.Fatalf("sizes = %d/%d, want 13/10", x.RequestSize, x.ResponseSize)
+	}
+	if x.RequestBody != `{"title":"a"}` || x.ResponseBody != `{"id":"1"}` {
+		t.Fatalf("bodies = %q / %q", x.RequestBody, x.ResponseBody)
+	}
+
+	for _, h := range []string{x.RequestHeaders.Get("Sb-Public-Key"), x.RequestHeaders.Get("X-Api-Key"), x.ResponseHeaders.Get("Set-Cookie")} {
+		if h != proxyRedacted {
+			t.Fatalf("header not redacted: %q", h)
+		}
+	}
+
+	b, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("log file has %d lines, want 2", len(lines))
+	}
+
+	var logged proxyExchange
+	if err := json.Unmarshal([]byte(lines[0]), &logged); err != nil {
+		t.Fatal(err)
+	}
+	if logged.Path != "/db/tasks" || strings.Contains(lines[0], "secret") {
+		t.Fatalf("unexpected log line: %s", lines[0])
+	}
+}
+
+func TestProxyBodyText(t *testing.T) {
+	cb := &cappedBuffer{limit: 4}
+	cb.Write([]byte("hello world"))
+	if got := proxyBodyText(cb, ""); got != "hell…[truncated]" {
+		t.Fatalf("truncated body = %q", got)
+	}
+
+	var zipped bytes.Buffer
+	zw := gzip.NewWriter(&zipped)
+	zw.Write([]byte(`{"ok":true}`))
+	zw.Close()
+
+	cb = &cappedBuffer{limit: 1024}
+	cb.Write(zipped.Bytes())
+	if got := proxyBodyText(cb, "gzip"); got != `{"ok":true}` {
+		t.Fatalf("gzip body = %q", got)
+	}
+
+	cb = &cappedBuffer{limit: 1024}
+	cb.Write([]byte{0xff, 0xfe, 0x00})
+	if got := proxyBodyText(cb, ""); got != "[binary body of 3 bytes]" {
+		t.Fatalf("binary body = %q", got)
+	}
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// proxyReadOnlyHeader is added to the responses of the requests blocked by
+// --read-only.
+const proxyReadOnlyHeader = "X-Proxy-Read-Only"
+
+// proxyAllow is a request permitted in read-only mode.
+type proxyAllow struct {
+	path    string
+	methods []string
+}
+
+// proxyReadOnlyReads are the endpoints reading data with a POST request.
+var proxyReadOnlyReads = []proxyAllow{
+	{path: "/login", methods: []string{http.MethodPost}},
+	{path: "/query/*", methods: []string{http.MethodPost}},
+	{path: "/sudoquery/*", methods: []string{http.MethodPost}},
+	{path: "/db/count/*", methods: []string{http.MethodPost}},
+	{path: "/search", methods: []string{http.MethodPost}},
+}
+
+// proxyReadOnlyWrites are the endpoints changing data with a GET request.
+var proxyReadOnlyWrites = []proxyAllow{
+	{path: "/storage/delete/*"},
+	{path: "/sudostorage/delete/*"},
+}
+
+// parseProxyAllow parses an --allow value, a path pattern optionally
+// preceded by comma-separated methods: "POST /fn/exec/report" or "/fn/*".
+func parseProxyAllow(s string) (proxyAllow, error) {
+	fields := strings.Fields(s)
+
+	switch len(fields) {
+	case 1:
+		if !strings.HasPrefix(fields[0], "/") {
+			return proxyAllow{}, fmt.Errorf("invalid allow %q: the path must start with /", s)
+		}
+		return proxyAllow{path: fields[0]}, nil
+	case 2:
+		if !strings.HasPrefix(fields[1], "/") {
+			return proxyAllow{}, fmt.Errorf("invalid allow %q: the path must start with /", s)
+		}
+		return proxyAllow{path: fields[1], methods: strings.Split(strings.ToUpper(fields[0]), ",")}, nil
+	}
+
+	return proxyAllow{}, fmt.Errorf(`invalid allow %q: expected "[METHODS] /path"`, s)
+}
+
+// proxyReadOnly rejects the requests that may change data, except the ones
+// allowed.
+type proxyReadOnly struct {
+	allow []proxyAllow
+}
+
+func newProxyReadOnly(allow []string) (*proxyReadOnly, error) {
+	ro := &proxyReadOnly{}
+	for _, s := range allow {
+		a, err := parseProxyAllow(s)
+		if err != nil {
+			return nil, err
+		}
+		ro.allow = append(ro.allow, a)
+	}
+	return ro, nil
+}
+
+// permitted reports whether a request is let through in read-only mode.
+func (ro *proxyReadOnly) permitted(method, path string) bool {
+	for _, a := range ro.allow {
+		if proxyMatch(a.path, a.methods, method, path) {
+			return true
+		}
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		for _, a := range proxyReadOnlyWrites {
+			if proxyMatch(a.path, a.methods, method, path) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, a := range proxyReadOnlyReads {
+		if proxyMatch(a.path, a.methods, method, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ro *proxyReadOnly) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ro.permitted(r.Method, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(proxyReadOnlyHeader, "blocked")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": fmt.Sprintf(`%s %s blocked by backend proxy --read-only, use --allow "%s %s" to permit it`,
+				r.Method, r.URL.Path, r.Method, r.URL.Path),
+		})
+	})
+}
+
+// printProxyTargetWarning warns when the proxy relays requests to a
+// production region.
+func printProxyTargetWarning(targets map[string]*url.URL, readOnly bool) {
+	var prod []string
+	for name, u := range targets {
+		if u != nil && !isLocalRegion(u.String()) {
+			prod = append(prod, fmt.Sprintf("%s (%s)", u.String(), name))
+		}
+	}
+	if len(prod) == 0 {
+		return
+	}
+	sort.Strings(prod)
+
+	fmt.Println()
+	printWarning("requests are relayed to PRODUCTION: %s", strings.Join(prod, ", "))
+	if !readOnly {
+		fmt.Println("Writes from your local app change production data, use --read-only to block them.")
+	}
+	fmt.Println()
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProxyReadOnly(t *testing.T) {
+	ro, err := newProxyReadOnly([]string{"post,put /fn/exec/*", "/sudo/cache"})
+	if err != nil {
+		t.Fatalf("newProxyReadOnly returned error: %v", err)
+	}
+
+	tests := []struct {
+		method, path string
+		want         bool
+	}{
+		{http.MethodGet, "/db/tasks", true},
+		{http.MethodHead, "/db/tasks", true},
+		{http.MethodPost, "/query/tasks", true},
+		{http.MethodPost, "/db/count/tasks", true},
+		{http.MethodPost, "/login", true},
+		{http.MethodPost, "/db/tasks", false},
+		{http.MethodPut, "/db/tasks/1", false},
+		{http.MethodDelete, "/db/tasks/1", false},
+		{http.MethodGet, "/storage/delete/1", false},
+		{http.MethodPost, "/fn/exec/report", true},
+		{http.MethodDelete, "/fn/exec/report", false},
+		{http.MethodPost, "/sudo/cache", true},
+	}
+
+	for _, tt := range tests {
+		if got := ro.permitted(tt.method, tt.path); got != tt.want {
+			t.Fatalf("permitted(%s %s) = %v, want %v", tt.method, tt.path, got, tt.want)
+		}
+	}
+
+	h := ro.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("blocked request was relayed")
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/db/tasks", nil))
+	if rec.Code != http.StatusForbidden || rec.Header().Get(proxyReadOnlyHeader) != "blocked" {
+		t.Fatalf("POST /db/tasks = %d %q, want 403 blocked", rec.Code, rec.Header().Get(proxyReadOnlyHeader))
+	}
+}
+
+func TestParseProxyAllow(t *testing.T) {
+	for _, s := range []string{"", "fn/exec", "POST fn/exec", "POST /a /b"} {
+		if _, err := parseProxyAllow(s); err == nil {
+			t.Fatalf("parseProxyAllow(%q) returned no error", s)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// proxyTargetHeader is added to the responses with the name of the target
+// that served the request.
+const proxyTargetHeader = "X-Proxy-Target"
+
+// proxyDefaultTarget is the name of the region config entry target.
+const proxyDefaultTarget = "default"
+
+// proxyRulesFile is the YAML file given to --rules:
+//
+//	targets:
+//	  prod:
+//	    url: na1
+//	  local:
+//	    url: http://localhost:8080
+//	    pubKey: dev_memory_pk
+//	rules:
+//	  - path: /db/*
+//	    methods: [POST, PUT, PATCH, DELETE]
+//	    target: local
+//	  - path: /fn/*
+//	    target: prod
+//	default: prod
+type proxyRulesFile struct {
+	Targets map[string]proxyRuleTarget `yaml:"targets"`
+	Rules   []proxyRule                `yaml:"rules"`
+	Default string                     `yaml:"default"`
+}
+
+type proxyRuleTarget struct {
+	URL    string `yaml:"url"`
+	PubKey string `yaml:"pubKey"`
+}
+
+type proxyRule struct {
+	Path    string   `yaml:"path"`
+	Methods []string `yaml:"methods"`
+	Target  string   `yaml:"target"`
+}
+
+// matches reports whether the rule applies to the request.
+func (rule proxyRule) matches(method, path string) bool {
+	return proxyMatch(rule.Path, rule.Methods, method, path)
+}
+
+// proxyMatch reports whether a request matches a path pattern and methods, a
+// pattern ending with * matches by prefix, otherwise exactly, and no methods
+// match them all.
+func proxyMatch(pattern string, methods []string, method, path string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		if !strings.HasPrefix(path, prefix) {
+			return false
+		}
+	} else if path != pattern {
+		return false
+	}
+
+	if len(methods) == 0 {
+		return true
+	}
+
+	for _, m := range methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyRoute is an upstream target of the router.
+type proxyRoute struct {
+	name    string
+	url     *url.URL
+	handler http.Handler
+}
+
+// proxyRouter relays each request to the target of the first matching rule.
+type proxyRouter struct {
+	rules  []proxyRule
+	routes map[string]proxyRoute
+	def    string
+}
+
+// loadProxyRules reads the rules file, fallback is the region config entry
+// target used when the file has no default, it may be nil. relay returns the
+// handler relaying requests to a target.
+func loadProxyRules(path string, fallback *url.URL, relay func(*url.URL) http.Handler) (*proxyRouter, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file proxyRulesFile
+	if err := yaml.UnmarshalStrict(b, &file); err != nil {
+		return nil, err
+	}
+
+	return newProxyRouter(file, fallback, relay)
+}
+
+func newProxyRouter(file proxyRulesFile, fallback *url.URL, relay func(*url.URL) http.Handler) (*proxyRouter, error) {
+	pr := &proxyRouter{
+		rules:  file.Rules,
+		routes: map[string]proxyRoute{},
+		def:    file.Default,
+	}
+
+	for name, t := range file.Targets {
+		if len(t.URL) == 0 {
+			return nil, fmt.Errorf("target %s has no url", name)
+		}
+
+		u, err := url.Parse(normalizeBackendRegion(t.URL))
+		if err != nil {
+			return nil, fmt.Errorf("target %s: invalid url: %w", name, err)
+		}
+
+		pr.routes[name] = proxyRoute{
+			name:    name,
+			url:     u,
+			handler: proxyRewritePublicKey(t.PubKey, relay(u)),
+		}
+	}
+
+	if len(pr.def) == 0 {
+		if fallback == nil {
+			return nil, fmt.Errorf("no default target: set default in the rules file or region in your config file")
+		}
+
+		pr.def = proxyDefaultTarget
+		if _, ok := pr.routes[pr.def]; !ok {
+			pr.routes[pr.def] = proxyRoute{name: pr.def, url: fallback, handler: relay(fallback)}
+		}
+	}
+
+	if _, ok := pr.routes[pr.def]; !ok {
+		return nil, fmt.Errorf("default target %s is not defined", pr.def)
+	}
+
+	for i, rule := range pr.rules {
+		if len(rule.Path) == 0 {
+			return nil, fmt.Errorf("rule #%d has no path", i+1)
+		}
+		if _, ok := pr.routes[rule.Target]; !ok {
+			return nil, fmt.Errorf("rule #%d: target %q is not defined", i+1, rule.Target)
+		}
+	}
+
+	return pr, nil
+}
+
+// route returns the target of the first rule matching the request.
+func (pr *proxyRouter) route(r *http.Request) proxyRoute {
+	for _, rule := range pr.rules {
+		if rule.matches(r.Method, r.URL.Path) {
+			return pr.routes[rule.Target]
+		}
+	}
+	return pr.routes[pr.def]
+}
+
+func (pr *proxyRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route := pr.route(r)
+
+	w.Header().Set(proxyTargetHeader, route.name)
+	route.handler.ServeHTTP(w, r)
+}
+
+// targets returns the URL of each target by name.
+func (pr *proxyRouter) targets() map[string]*url.URL {
+	targets := map[string]*url.URL{}
+	for name, route := range pr.routes {
+		targets[name] = route.url
+	}
+	return targets
+}
+
+// summary describes the rules, printed when the proxy starts.
+func (pr *proxyRouter) summary() string {
+	var sb strings.Builder
+
+	for _, rule := range pr.rules {
+		methods := "*"
+		if len(rule.Methods) > 0 {
+			methods = strings.ToUpper(strings.Join(rule.Methods, ","))
+		}
+		fmt.Fprintf(&sb, "  %s %s -> %s\n", methods, rule.Path, rule.Target)
+	}
+	fmt.Fprintf(&sb, "  * -> %s\n", pr.def)
+
+	return sb.String()
+}
+
+// proxyRewritePublicKey replaces the SB-PUBLIC-KEY header of the requests,
+// an empty key keeps it unchanged.
+func proxyRewritePublicKey(pubKey string, next http.Handler) http.Handler {
+	if len(pubKey) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set("SB-PUBLIC-KEY", pubKey)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestProxyRouter(t *testing.T) {
+	upstream := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(name + " " + r.Header.Get("SB-PUBLIC-KEY")))
+		}))
+	}
+
+	local, prod := upstream("local"), upstream("prod")
+	defer local.Close()
+	defer prod.Close()
+
+	router, err := newProxyRouter(proxyRulesFile{
+		Targets: map[string]proxyRuleTarget{
+			"local": {URL: local.URL, PubKey: "dev_memory_pk"},
+			"prod":  {URL: prod.URL},
+		},
+		Rules: []proxyRule{
+			{Path: "/db/*", Methods: []string{"post", "DELETE"}, Target: "local"},
+			{Path: "/fn/*", Target: "prod"},
+			{Path: "/me", Target: "local"},
+		},
+		Default: "prod",
+	}, nil, testProxyRelay)
+	if err != nil {
+		t.Fatalf("newProxyRouter returned error: %v", err)
+	}
+
+	tests := []struct {
+		method, path, want string
+	}{
+		{http.MethodPost, "/db/tasks", "local dev_memory_pk"},
+		{http.MethodDelete, "/db/tasks/1", "local dev_memory_pk"},
+		{http.MethodGet, "/db/tasks", "prod prod-key"},
+		{http.MethodGet, "/fn/exec/hello", "prod prod-key"},
+		{http.MethodGet, "/me", "local dev_memory_pk"},
+		{http.MethodGet, "/me/too", "prod prod-key"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("SB-PUBLIC-KEY", "prod-key")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if got := rec.Body.String(); got != tt.want {
+			t.Fatalf("%s %s routed to %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+
+		wantTarget := strings.Fields(tt.want)[0]
+		if got := rec.Header().Get(proxyTargetHeader); got != wantTarget {
+			t.Fatalf("%s %s %s = %q, want %q", tt.method, tt.path, proxyTargetHeader, got, wantTarget)
+		}
+	}
+}
+
+func testProxyRelay(target *url.URL) http.Handler {
+	return newProxyHandler(target, http.DefaultTransport, false)
+}
+
+func TestProxyRouterErrors(t *testing.T) {
+	fallback, _ := url.Parse("https://na1.staticbackend.dev")
+
+	tests := map[string]proxyRulesFile{
+		"no default target": {},
+		"is not defined": {
+			Rules: []proxyRule{{Path: "/db/*", Target: "local"}},
+		},
+		"has no url": {
+			Targets: map[string]proxyRuleTarget{"local": {}},
+		},
+	}
+
+	for want, file := range tests {
+		var fb *url.URL
+		if want != "no default target" {
+			fb = fallback
+		}
+
+		_, err := newProxyRouter(file, fb, testProxyRelay)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("newProxyRouter error = %v, want it to contain %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestNewProxyHandlerCORS(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Write([]byte("ok"))
+	}))
+	defer target.Close()
+
+	u, _ := url.Parse(target.URL)
+	origins := []string{"http://localhost:3000"}
+	h := devCORS(origins, newProxyHandler(u, http.DefaultTransport, true))
+
+	req := httptest.NewRequest(http.MethodGet, "/db/tasks", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got := rec.Header().Values("Access-Control-Allow-Origin"); len(got) != 1 || got[0] != "http://localhost:3000" {
+		t.Fatalf("Access-Control-Allow-Origin = %v, want only the proxy origin", got)
+	}
+}
+
+func TestProxySelfSignedCert(t *testing.T) {
+	cert, fingerprint, err := proxySelfSignedCert("192.168.1.20")
+	if err != nil {
+		t.Fatalf("proxySelfSignedCert returned error: %v", err)
+	}
+
+	if len(fingerprint) != 95 {
+		t.Fatalf("fingerprint = %q, want 32 colon-separated bytes", fingerprint)
+	}
+
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("unable to parse the certificate: %v", err)
+	}
+
+	for _, host := range []string{"localhost", "127.0.0.1", "::1", "192.168.1.20"} {
+		if err := parsed.VerifyHostname(host); err != nil {
+			t.Fatalf("certificate is not valid for %s: %v", host, err)
+		}
+	}
+
+	if err := parsed.VerifyHostname("example.com"); err == nil {
+		t.Fatal("certificate is valid for example.com")
+	}
+}
+
+func TestProxyDisplayHost(t *testing.T) {
+	tests := map[string]string{
+		"":             "localhost",
+		"0.0.0.0":      "localhost",
+		"::":           "localhost",
+		"localhost":    "localhost",
+		"192.168.1.20": "192.168.1.20",
+	}
+
+	for host, want := range tests {
+		if got := proxyDisplayHost(host); got != want {
+			t.Fatalf("proxyDisplayHost(%q) = %q, want %q", host, got, want)
+		}
+	}
+
+	if got := net.JoinHostPort(proxyDisplayHost("::1"), "8099"); got != "[::1]:8099" {
+		t.Fatalf("display address = %q", got)
+	}
+}
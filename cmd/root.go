@@ -85,11 +85,14 @@ func Execute() {
 func init() {
 	cobra.OnInitialize(initConfig)
 
+	rootCmd.PersistentPreRun = checkAccountFlag
+
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $PWD/.backend.yml)")
+	rootCmd.PersistentFlags().StringVar(&accountFlag, "account", "", "account ID the users, db, and function run commands operate on (requires rootToken)")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
-	"time"
+	"os"
+	"sync/atomic"
 
+	"github.com/staticbackendhq/cli/devserver"
 	staticbackend "github.com/staticbackendhq/core"
 	sbconfig "github.com/staticbackendhq/core/config"
 	"github.com/staticbackendhq/core/logger"
@@ -12,7 +15,15 @@ import (
 	"github.com/spf13/cobra"
 )
 
-const devAppSecret = "staticbackend-cli-dev-secret-32b"
+const devAppSecret = devserver.AppSecret
+
+// credentials of the admin account created when the dev server starts
+const (
+	devPublicKey     = devserver.PublicKey
+	devRootToken     = devserver.RootToken
+	devAdminEmail    = devserver.AdminEmail
+	devAdminPassword = devserver.AdminPassword
+)
 
 // serverCmd represents the server command
 var serverCmd = &cobra.Command{
@@ -29,40 +40,50 @@ when going from local to production.
 There are some limitations that you can learn more about here.
 
 https://staticbackend.dev/cli
+
+Every flag may also be set in a server section of your .backend.yml file:
+
+	server:
+	  port: 8099
+	  persistData: true
+	  dbPath: ./data/local.db
+	  dataDir: ./data
+	  logLevel: warn
+	  logFormat: json
+	  corsOrigins:
+	    - http://localhost:3000
+	  seed: ./seed
+
+The --seed directory is loaded once the server is ready, giving everyone the
+same local dataset:
+
+	seed/
+	  db/<repo>.json                array of documents
+	  db/<repo>.ndjson              one document per line
+	  users.csv                     email, password, role as for "users import"
+	  functions/<name>.js           function with the web trigger
+	  functions/<topic>/<name>.js   function triggered by topic
+	  tasks.yml                     tasks as for "task apply"
+
+Documents and users are created in the admin@dev.com account, users without
+a password get the admin's password, %s. Existing repositories, users,
+functions and tasks are skipped.
+
+Once the server accepts requests a line starting with READY followed by a
+JSON object with the URL and dev credentials is printed. Use --ready-file to
+also write it to a file, and "backend server status --wait-ready" to wait
+for the server from a script.
+
+Emails sent via the mail API, and the ones the server sends itself like
+password resets, are captured instead of being delivered, view them with
+"backend mail inbox" or at /_inbox/ on the server's URL. Text
+messages are captured as well, view them with "backend sms inbox".
 	`,
 		clbold("StaticBackend development server"),
+		clbold(devAdminPassword),
 	),
 	Run: func(cmd *cobra.Command, args []string) {
-		f := cmd.Flag("port")
-		persistData := cmd.Flag("persist-data").Value.String() == "true"
-
-		uri := fmt.Sprintf(
-			"http://localhost:%s/account/init?email=admin@dev.com&mem=1",
-			f.Value.String(),
-		)
-
-		go createCustomer(uri, f.Value.String())
-
-		c := sbconfig.AppConfig{
-			AppEnv:          "dev",
-			AppSecret:       devAppSecret,
-			FromCLI:         "yes",
-			Port:            f.Value.String(),
-			DatabaseURL:     "mem",
-			DataStore:       "mem",
-			RedisHost:       "mem",
-			LocalStorageURL: "http://localhost:8099",
-			ActivateFlag:    "no-stripe-test-flag",
-		}
-
-		if persistData {
-			c.DatabaseURL = "local.db"
-			c.DataStore = "sqlite"
-		}
-
-		log := logger.Get(c)
-
-		staticbackend.Start(c, log)
+		runDevServer()
 	},
 }
 
@@ -77,27 +98,168 @@ func init() {
 
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
-	serverCmd.Flags().Int32P("port", "p", 8099, "dev server port")
-	serverCmd.Flags().Bool("no-log", false, "prevents printing requests/responses info")
-	serverCmd.Flags().Bool("persist-data", false, "persists data across usage")
+	addDevServerFlags(serverCmd)
 }
 
-func createCustomer(uri, port string) {
-	fmt.Printf("server started at: %s\n\n", clbold("http://localhost:"+port))
-
-	time.Sleep(500 * time.Millisecond)
+// runDevServer starts the dev server in the foreground.
+func runDevServer() {
+	opts, err := getDevServerOptions()
+	if err != nil {
+		printError("invalid server configuration: %v", err)
+		return
+	}
 
-	req, err := http.NewRequest("GET", uri, nil)
+	// the StaticBackend server listens on an internal port, the dev port
+	// is served by a front handler capturing emails and text messages
+	corePort, err := devFreePort()
 	if err != nil {
-		fmt.Println(err)
+		printError("unable to find a free port: %v", err)
+		return
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	readyFile := devReadyFilePath(opts.readyFile)
+	if len(readyFile) > 0 {
+		// a stale file would tell scripts we're ready already
+		if err := removeIfExists(readyFile); err != nil {
+			printError("unable to remove the previous ready file: %v", err)
+			return
+		}
+	}
+
+	// the server sends its own emails with its SES provider, pointed at
+	// a local endpoint capturing them in the same mailbox
+	sesPort, err := devFreePort()
 	if err != nil {
-		fmt.Println(err)
+		printError("unable to find a free port: %v", err)
 		return
 	}
-	defer resp.Body.Close()
+
+	mailbox := newDevMailbox()
+
+	var ready atomic.Bool
+	go startDevFrontend(opts, corePort, mailbox, &ready)
+	go startDevSESCapture(sesPort, mailbox)
+	go bootstrapDevServer(opts, readyFile, &ready)
+
+	c := opts.appConfig(corePort)
+	devUseSESCapture(&c, sesPort)
+
+	log := logger.Get(c)
+
+	staticbackend.Start(c, log)
+}
+
+func startDevFrontend(opts devServerOptions, corePort string, mailbox *devMailbox, ready *atomic.Bool) {
+	h, err := devFrontend(opts, corePort, mailbox, ready)
+	if err != nil {
+		printError("invalid dev server address: %v", err)
+		os.Exit(1)
+	}
+
+	if err := http.ListenAndServe(":"+opts.port, h); err != nil {
+		printError("unable to start the dev server: %v", err)
+		os.Exit(1)
+	}
+}
+
+func startDevSESCapture(port string, mailbox *devMailbox) {
+	if err := http.ListenAndServe("localhost:"+port, mailbox.sesHandler()); err != nil {
+		printError("unable to start the email capture: %v", err)
+		os.Exit(1)
+	}
+}
+
+// devMailProviderSES is the StaticBackend mail provider using AWS SES.
+const devMailProviderSES = "ses"
+
+// devUseSESCapture points the SES mail provider of the server at the email
+// capture listening on port. The credentials are only needed to sign the
+// requests, they are set when missing.
+func devUseSESCapture(c *sbconfig.AppConfig, port string) {
+	c.MailProvider = devMailProviderSES
+
+	os.Setenv("AWS_ENDPOINT_URL_SES", "http://localhost:"+port)
+	for k, v := range map[string]string{
+		"AWS_REGION":            "us-east-1",
+		"AWS_ACCESS_KEY_ID":     "dev",
+		"AWS_SECRET_ACCESS_KEY": "dev",
+	} {
+		if len(os.Getenv(k)) == 0 {
+			os.Setenv(k, v)
+		}
+	}
+}
+
+// bootstrapDevServer waits for the server, creates the admin account, loads
+// the seed and announces the server is ready, setting ready for the health
+// endpoint.
+func bootstrapDevServer(opts devServerOptions, readyFile string, ready *atomic.Bool) {
+	base := "http://localhost:" + opts.port
+
+	if err := devWaitReady(base, devReadyTimeout); err != nil {
+		printError("the dev server did not start: %v", err)
+		os.Exit(1)
+	}
+
+	if err := createCustomer(base, opts.port); err != nil {
+		printError("unable to create the %s account: %v", devAdminEmail, err)
+		os.Exit(1)
+	}
+
+	if len(opts.seedDir) > 0 {
+		seedDevServer(opts.port, opts.seedDir)
+	}
+
+	ready.Store(true)
+
+	info := newDevReadyInfo(opts.port)
+
+	fmt.Printf("server started at: %s\n\n", clbold(info.URL))
+	fmt.Printf("captured emails: %s\n\n", clbold(info.Inbox))
+
+	b, err := json.Marshal(info)
+	if err != nil {
+		printError("unable to encode the ready line: %v", err)
+	} else {
+		fmt.Printf("READY %s\n\n", b)
+	}
+
+	if len(readyFile) > 0 {
+		if err := writeDevReadyFile(readyFile, info); err != nil {
+			printError("unable to write the ready file: %v", err)
+		}
+	}
+
+	// set by "backend server start --detach" waiting for us
+	if path := os.Getenv(devStateEnv); len(path) > 0 {
+		if err := markDevServerReady(path); err != nil {
+			printError("unable to update the state file: %v", err)
+		}
+	}
 
 	fmt.Printf("press CTRL+C to quit and close server\n\n")
 }
+
+// createCustomer creates the admin account, retrying until the server
+// accepts it. With persisted data the account may already exist.
+func createCustomer(base, port string) error {
+	uri := fmt.Sprintf("%s/account/init?email=%s&mem=1", base, devAdminEmail)
+
+	return devRetry(devReadyTimeout, func() error {
+		resp, err := http.Get(uri)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 300 {
+			return nil
+		}
+
+		if devAdminReady(port) == nil {
+			return nil
+		}
+
+		return fmt.Errorf("account init returned %s", resp.Status)
+	})
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/staticbackendhq/cli/devserver"
+	sbconfig "github.com/staticbackendhq/core/config"
+)
+
+// defaultDevDataDir holds the dev server local state, relative to the
+// working directory.
+const defaultDevDataDir = ".staticbackend"
+
+// devServerOptions are the dev server settings, read from the server flags
+// or the server section of the config file.
+type devServerOptions struct {
+	port        string
+	persistData bool
+	dataDir     string
+	dbPath      string
+	storageURL  string
+	logLevel    string
+	logFormat   string
+	noLog       bool
+	corsOrigins []string
+	appSecret   string
+	seedDir     string
+	readyFile   string
+}
+
+// devServerConfigKeys maps the server flags to their config file entries.
+var devServerConfigKeys = map[string]string{
+	"port":         "server.port",
+	"persist-data": "server.persistData",
+	"data-dir":     "server.dataDir",
+	"db-path":      "server.dbPath",
+	"storage-url":  "server.storageURL",
+	"log-level":    "server.logLevel",
+	"log-format":   "server.logFormat",
+	"no-log":       "server.noLog",
+	"cors-origin":  "server.corsOrigins",
+	"app-secret":   "server.appSecret",
+	"seed":         "server.seed",
+	"ready-file":   "server.readyFile",
+}
+
+func addDevServerFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().Int32P("port", "p", 8099, "dev server port")
+	cmd.PersistentFlags().Bool("persist-data", false, "persists data across usage")
+	cmd.PersistentFlags().String("data-dir", defaultDevDataDir, "directory of the captured emails and text messages")
+	cmd.PersistentFlags().String("db-path", "local.db", "sqlite database path used with --persist-data")
+	cmd.PersistentFlags().String("storage-url", "", "public URL of uploaded files (default http://localhost:<port>)")
+	cmd.PersistentFlags().String("log-level", "info", "server log level: debug, info, warn, or error")
+	cmd.PersistentFlags().String("log-format", "text", "request log format: text or json")
+	cmd.PersistentFlags().Bool("no-log", false, "prevents printing requests/responses info")
+	cmd.PersistentFlags().StringSlice("cors-origin", nil, "allowed CORS origins, may be repeated (default any origin)")
+	cmd.PersistentFlags().String("app-secret", devAppSecret, "32 bytes secret used to encrypt sensitive data")
+	cmd.PersistentFlags().String("ready-file", "", "file written with the URL and dev credentials once the server is ready, relative to --data-dir")
+	cmd.PersistentFlags().String("seed", "", "directory of documents, users, functions and tasks loaded once the server is ready")
+
+	for flag, key := range devServerConfigKeys {
+		if err := viper.BindPFlag(key, cmd.PersistentFlags().Lookup(flag)); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func getDevServerOptions() (devServerOptions, error) {
+	opts := devServerOptions{
+		port:        cleanConfigValue(viper.GetString("server.port")),
+		persistData: viper.GetBool("server.persistData"),
+		dataDir:     devDataDir(),
+		dbPath:      cleanConfigValue(viper.GetString("server.dbPath")),
+		storageURL:  cleanConfigValue(viper.GetString("server.storageURL")),
+		logLevel:    strings.ToLower(cleanConfigValue(viper.GetString("server.logLevel"))),
+		logFormat:   strings.ToLower(cleanConfigValue(viper.GetString("server.logFormat"))),
+		noLog:       viper.GetBool("server.noLog"),
+		corsOrigins: viper.GetStringSlice("server.corsOrigins"),
+		appSecret:   cleanConfigValue(viper.GetString("server.appSecret")),
+		seedDir:     cleanConfigValue(viper.GetString("server.seed")),
+		readyFile:   cleanConfigValue(viper.GetString("server.readyFile")),
+	}
+
+	return opts, opts.validate()
+}
+
+func (opts devServerOptions) validate() error {
+	switch opts.logLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("invalid log level %q: must be debug, info, warn, or error", opts.logLevel)
+	}
+
+	switch opts.logFormat {
+	case "text", "json":
+	default:
+		return fmt.Errorf("invalid log format %q: must be text or json", opts.logFormat)
+	}
+
+	if len(opts.appSecret) != 32 {
+		return fmt.Errorf("the app secret must be 32 bytes long, it is %d", len(opts.appSecret))
+	}
+
+	if len(opts.port) == 0 {
+		return fmt.Errorf("the port cannot be empty")
+	}
+
+	if len(opts.seedDir) > 0 {
+		if fi, err := os.Stat(opts.seedDir); err != nil {
+			return fmt.Errorf("invalid seed directory: %w", err)
+		} else if !fi.IsDir() {
+			return fmt.Errorf("the seed %s is not a directory", opts.seedDir)
+		}
+	}
+
+	return nil
+}
+
+// devDataDir returns the directory where the dev server keeps its state.
+func devDataDir() string {
+	dir := cleanConfigValue(viper.GetString("server.dataDir"))
+	if len(dir) == 0 {
+		return defaultDevDataDir
+	}
+	return dir
+}
+
+// appConfig maps the options to the StaticBackend configuration, the
+// server itself listens on corePort.
+func (opts devServerOptions) appConfig(corePort string) sbconfig.AppConfig {
+	c := devserver.AppConfig(corePort)
+	c.AppSecret = opts.appSecret
+	c.LocalStorageURL = opts.storageURL
+	c.LogConsoleLevel = opts.logLevel
+
+	if len(c.LocalStorageURL) == 0 {
+		c.LocalStorageURL = "http://localhost:" + opts.port
+	}
+
+	if opts.persistData {
+		c.DatabaseURL = opts.dbPath
+		c.DataStore = "sqlite"
+	}
+
+	return c
+}
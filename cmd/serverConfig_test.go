@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testDevServerOptions() devServerOptions {
+	return devServerOptions{
+		port:      "9000",
+		dataDir:   defaultDevDataDir,
+		dbPath:    "local.db",
+		logLevel:  "info",
+		logFormat: "text",
+		appSecret: devAppSecret,
+	}
+}
+
+func TestDevServerAppConfig(t *testing.T) {
+	opts := testDevServerOptions()
+
+	c := opts.appConfig("12345")
+	if c.Port != "12345" {
+		t.Fatalf("appConfig Port = %q, want the core port", c.Port)
+	}
+	if c.LocalStorageURL != "http://localhost:9000" {
+		t.Fatalf("appConfig LocalStorageURL = %q, want it derived from the dev port", c.LocalStorageURL)
+	}
+	if c.DataStore != "mem" {
+		t.Fatalf("appConfig DataStore = %q, want mem", c.DataStore)
+	}
+
+	opts.persistData = true
+	opts.dbPath = "data/dev.db"
+	opts.storageURL = "https://files.example.com"
+
+	c = opts.appConfig("12345")
+	if c.DataStore != "sqlite" || c.DatabaseURL != "data/dev.db" {
+		t.Fatalf("appConfig with persisted data = %q %q", c.DataStore, c.DatabaseURL)
+	}
+	if c.LocalStorageURL != "https://files.example.com" {
+		t.Fatalf("appConfig LocalStorageURL = %q, want the configured URL", c.LocalStorageURL)
+	}
+}
+
+func TestDevServerOptionsValidate(t *testing.T) {
+	if err := testDevServerOptions().validate(); err != nil {
+		t.Fatalf("validate returned error: %v", err)
+	}
+
+	invalid := []func(*devServerOptions){
+		func(o *devServerOptions) { o.logLevel = "verbose" },
+		func(o *devServerOptions) { o.logFormat = "xml" },
+		func(o *devServerOptions) { o.appSecret = "too-short" },
+		func(o *devServerOptions) { o.port = "" },
+	}
+
+	for i, change := range invalid {
+		opts := testDevServerOptions()
+		change(&opts)
+		if err := opts.validate(); err == nil {
+			t.Fatalf("validate returned nil error for invalid options #%d", i)
+		}
+	}
+}
+
+func TestDevCORS(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	h := devCORS([]string{"http://localhost:3000/"}, next)
+
+	req := httptest.NewRequest(http.MethodOptions, "/db/tasks", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("preflight returned %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "http://localhost:3000" {
+		t.Fatalf("preflight Access-Control-Allow-Origin = %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodOptions, "/db/tasks", nil)
+	req.Header.Set("Origin", "http://evil.example.com")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("preflight from an unknown origin returned %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
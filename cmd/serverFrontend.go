@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+func devFreePort() (string, error) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+
+	return strconv.Itoa(l.Addr().(*net.TCPAddr).Port), nil
+}
+
+// devFrontend is the handler listening on the dev port. It captures emails
+// and text messages, handles CORS, logs requests, and relays everything else
+// to the StaticBackend server listening on corePort. ready is set once the
+// server is bootstrapped.
+func devFrontend(opts devServerOptions, corePort string, mailbox *devMailbox, ready *atomic.Bool) (http.Handler, error) {
+	target, err := url.Parse("http://localhost:" + corePort)
+	if err != nil {
+		return nil, err
+	}
+
+	rp := httputil.NewSingleHostReverseProxy(target)
+	if len(opts.corsOrigins) > 0 {
+		// our CORS headers replace the permissive ones of the server
+		rp.ModifyResponse = devStripCORS
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/sudo/sendmail", mailbox.captureHandler())
+	mux.Handle("/sudo/sms", newDevSMSBox().captureHandler())
+	mux.Handle("/_inbox/", mailbox.webHandler())
+	mux.Handle(devHealthPath, devHealthHandler(target, ready))
+	mux.Handle("/", rp)
+
+	var h http.Handler = mux
+	if len(opts.corsOrigins) > 0 {
+		h = devCORS(opts.corsOrigins, h)
+	}
+	if !opts.noLog {
+		h = devRequestLog(opts.logFormat, h)
+	}
+
+	return h, nil
+}
+
+func devCORS(origins []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if len(origin) > 0 && devOriginAllowed(origins, origin) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			w.Header().Add("Vary", "Origin")
+
+			if r.Method == http.MethodOptions {
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, SB-PUBLIC-KEY")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		} else if r.Method == http.MethodOptions && len(origin) > 0 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// devStripCORS removes the CORS headers of a relayed response.
+func devStripCORS(resp *http.Response) error {
+	for k := range resp.Header {
+		if strings.HasPrefix(k, "Access-Control-") {
+			resp.Header.Del(k)
+		}
+	}
+	return nil
+}
+
+func devOriginAllowed(origins []string, origin string) bool {
+	for _, o := range origins {
+		if o == "*" || strings.EqualFold(strings.TrimSuffix(o, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+type devStatusRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func (rec *devStatusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *devStatusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.size += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (rec *devStatusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+func (rec *devStatusRecorder) Flush() {
+	if f, ok := rec.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func devRequestLog(format string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// readiness probes would flood the log
+		if r.URL.Path == devHealthPath {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		rec := &devStatusRecorder{ResponseWriter: w}
+
+		next.ServeHTTP(rec, r)
+
+		if format == "json" {
+			b, _ := json.Marshal(map[string]any{
+				"time":     start.Format(time.RFC3339),
+				"method":   r.Method,
+				"path":     r.URL.Path,
+				"status":   rec.status,
+				"size":     rec.size,
+				"duration": time.Since(start).Milliseconds(),
+			})
+			fmt.Println(string(b))
+			return
+		}
+
+		fmt.Printf("%s %s %s %d %dB %v\n",
+			start.Format("15:04:05"),
+			r.Method,
+			r.URL.Path,
+			rec.status,
+			rec.size,
+			time.Since(start).Round(time.Millisecond),
+		)
+	})
+}
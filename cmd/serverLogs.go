@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var serverLogsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Display the output of the dev server started with --detach",
+	Long: fmt.Sprintf(`
+%s
+
+Prints the server.log file of the data directory.
+
+$> backend server logs -n 50
+$> backend server logs --follow
+	`,
+		clbold("Dev server logs"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		lines, _ := cmd.Flags().GetInt("lines")
+		follow, _ := cmd.Flags().GetBool("follow")
+
+		path := devLogPath()
+		if state, running, err := runningDevServerState(); err == nil && running {
+			path = state.LogFile
+		}
+
+		f, err := os.Open(path)
+		if os.IsNotExist(err) {
+			printError("no log file found at %s, start the server with \"backend server start --detach\"", path)
+			return
+		} else if err != nil {
+			printError("unable to read the logs: %v", err)
+			return
+		}
+		defer f.Close()
+
+		tail, err := lastLines(f, lines)
+		if err != nil {
+			printError("unable to read the logs: %v", err)
+			return
+		}
+
+		for _, line := range tail {
+			fmt.Println(line)
+		}
+
+		if follow {
+			followFile(f, os.Stdout)
+		}
+	},
+}
+
+func init() {
+	serverCmd.AddCommand(serverLogsCmd)
+
+	serverLogsCmd.Flags().IntP("lines", "n", 0, "number of lines from the end to display, 0 for all")
+	serverLogsCmd.Flags().BoolP("follow", "f", false, "keep printing new lines as they are written")
+}
+
+// lastLines returns the last n lines of r, or all lines when n is 0. The
+// reader is left at its end.
+func lastLines(r io.Reader, n int) ([]string, error) {
+	var lines []string
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+		if n > 0 && len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+
+	return lines, sc.Err()
+}
+
+// followFile copies what's appended to f into w until interrupted.
+func followFile(f *os.File, w io.Writer) {
+	for {
+		if _, err := io.Copy(w, f); err != nil {
+			printError("unable to read the logs: %v", err)
+			return
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
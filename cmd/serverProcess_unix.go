@@ -0,0 +1,26 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"errors"
+	"syscall"
+)
+
+func devDetachAttr() *syscall.SysProcAttr {
+	// a new session so the server survives the terminal being closed
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+func devProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+func devStopProcess(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
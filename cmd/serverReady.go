@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/staticbackendhq/backend-go"
+)
+
+// devHealthPath is answered by the dev port once the StaticBackend server
+// accepts requests, its ready field is set once the admin account and the
+// seed are loaded.
+const devHealthPath = "/_health"
+
+// devReadyTimeout is how long the bootstrap waits for the server.
+const devReadyTimeout = 30 * time.Second
+
+// devReadyInfo is printed on the READY line and written to the ready file
+// once the dev server accepts requests.
+type devReadyInfo struct {
+	URL       string `json:"url"`
+	PublicKey string `json:"pubKey"`
+	RootToken string `json:"rootToken"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	Inbox     string `json:"inbox"`
+	PID       int    `json:"pid,omitempty"`
+}
+
+func newDevReadyInfo(port string) devReadyInfo {
+	base := "http://localhost:" + port
+	return devReadyInfo{
+		URL:       base,
+		PublicKey: devPublicKey,
+		RootToken: devRootToken,
+		Email:     devAdminEmail,
+		Password:  devAdminPassword,
+		Inbox:     base + "/_inbox/",
+		PID:       os.Getpid(),
+	}
+}
+
+// devHealth is the body of the health endpoint.
+type devHealth struct {
+	Status string `json:"status"`
+	Ready  bool   `json:"ready"`
+}
+
+// devHealthHandler reports whether the StaticBackend server on core
+// accepts requests, and whether the bootstrap completed.
+func devHealthHandler(core *url.URL, ready *atomic.Bool) http.Handler {
+	client := &http.Client{Timeout: 2 * time.Second}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		health, code := devHealth{Status: "ok", Ready: ready.Load()}, http.StatusOK
+
+		resp, err := client.Get(core.String() + "/")
+		if err != nil {
+			health, code = devHealth{Status: "starting"}, http.StatusServiceUnavailable
+		} else {
+			resp.Body.Close()
+			if !health.Ready {
+				health.Status = "bootstrapping"
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(health)
+	})
+}
+
+// devProbe checks once whether the dev server at base accepts requests.
+func devProbe(base string) error {
+	_, err := devHealthCheck(base)
+	return err
+}
+
+// devProbeReady checks once whether the dev server at base accepts requests
+// and has loaded its admin account and seed.
+func devProbeReady(base string) error {
+	health, err := devHealthCheck(base)
+	if err != nil {
+		return err
+	}
+	if !health.Ready {
+		return fmt.Errorf("the server is still creating the admin account and loading the seed")
+	}
+	return nil
+}
+
+func devHealthCheck(base string) (devHealth, error) {
+	var health devHealth
+
+	client := http.Client{Timeout: 2 * time.Second}
+
+	resp, err := client.Get(base + devHealthPath)
+	if err != nil {
+		return health, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return health, fmt.Errorf("health check returned %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		return health, fmt.Errorf("invalid health check response: %w", err)
+	}
+	return health, nil
+}
+
+// devBackoff returns the delay before the attempt following attempt n,
+// doubling from 50ms up to one second.
+func devBackoff(n int) time.Duration {
+	d := 50 * time.Millisecond
+	for i := 0; i < n && d < time.Second; i++ {
+		d *= 2
+	}
+	if d > time.Second {
+		d = time.Second
+	}
+	return d
+}
+
+// devRetry calls fn with backoff until it succeeds or timeout elapses, the
+// last error is returned.
+func devRetry(timeout time.Duration, fn func() error) error {
+	deadline := time.Now().Add(timeout)
+
+	for n := 0; ; n++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		wait := devBackoff(n)
+		if time.Now().Add(wait).After(deadline) {
+			return fmt.Errorf("not ready after %v: %w", timeout, err)
+		}
+		time.Sleep(wait)
+	}
+}
+
+// devWaitReady waits for the dev server at base to accept requests.
+func devWaitReady(base string, timeout time.Duration) error {
+	return devRetry(timeout, func() error { return devProbe(base) })
+}
+
+// devReadyFilePath returns the ready file path, relative paths are in the
+// data directory.
+func devReadyFilePath(path string) string {
+	if len(path) == 0 || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(devDataDir(), path)
+}
+
+func writeDevReadyFile(path string, info devReadyInfo) error {
+	b, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	// written then renamed so readers never see a partial file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// devServerRunning reports whether a dev server is ready on port.
+func devServerRunning(port string) bool {
+	return devProbe("http://localhost:"+port) == nil
+}
+
+// devAdminReady reports whether the admin account of the dev server at
+// port accepts logins.
+func devAdminReady(port string) error {
+	devUseServer(port)
+	_, err := backend.Login(devAdminEmail, devAdminPassword)
+	return err
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDevBackoff(t *testing.T) {
+	tests := map[int]time.Duration{
+		0:  50 * time.Millisecond,
+		1:  100 * time.Millisecond,
+		3:  400 * time.Millisecond,
+		5:  time.Second,
+		50: time.Second,
+	}
+
+	for n, want := range tests {
+		if got := devBackoff(n); got != want {
+			t.Fatalf("devBackoff(%d) = %v, want %v", n, got, want)
+		}
+	}
+}
+
+func TestDevRetry(t *testing.T) {
+	calls := 0
+	err := devRetry(5*time.Second, func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("not yet")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("devRetry = %v after %d calls, want success after 3", err, calls)
+	}
+
+	err = devRetry(100*time.Millisecond, func() error { return errors.New("down") })
+	if err == nil {
+		t.Fatal("devRetry returned nil error for a failing check")
+	}
+}
+
+func TestDevProbe(t *testing.T) {
+	var coreUp atomic.Bool
+	core := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !coreUp.Load() {
+			panic(http.ErrAbortHandler)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer core.Close()
+
+	var ready atomic.Bool
+	target, _ := url.Parse(core.URL)
+	front := httptest.NewServer(devHealthHandler(target, &ready))
+	defer front.Close()
+
+	if err := devProbe(front.URL); err == nil {
+		t.Fatal("devProbe returned nil error while the server is starting")
+	}
+
+	coreUp.Store(true)
+	if err := devProbe(front.URL); err != nil {
+		t.Fatalf("devProbe returned error once the server is up: %v", err)
+	}
+	if err := devProbeReady(front.URL); err == nil {
+		t.Fatal("devProbeReady returned nil error before the bootstrap completed")
+	}
+
+	ready.Store(true)
+	if err := devProbeReady(front.URL); err != nil {
+		t.Fatalf("devProbeReady returned error once bootstrapped: %v", err)
+	}
+}
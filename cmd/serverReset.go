@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var serverResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Delete all the dev server data",
+	Long: fmt.Sprintf(`
+%s
+
+With --persist-data the sqlite database file is deleted, the server must be
+stopped. In memory mode the server must be running, every document of every
+repository is deleted via the API.
+
+Captured emails and text messages are kept, use "backend mail inbox clear"
+and "backend sms inbox clear" to delete them.
+
+Save a snapshot first if you may need the data back:
+
+$> backend server snapshot save before-reset
+$> backend server reset
+	`,
+		clbold("Reset the dev server"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		opts, err := getDevServerOptions()
+		if err != nil {
+			printError("invalid server configuration: %v", err)
+			return
+		}
+
+		running := devServerRunning(opts.port)
+
+		if opts.store() == devStoreSQLite {
+			if running {
+				printError("the server is running on port %s, stop it before resetting its sqlite database", opts.port)
+				return
+			}
+
+			for _, path := range devSQLiteFiles(opts.dbPath) {
+				if err := removeIfExists(path); err != nil {
+					printError("unable to delete %s: %v", path, err)
+					return
+				}
+			}
+
+			printSuccess("database %s deleted, it will be recreated on the next start", clbold(opts.dbPath))
+			return
+		}
+
+		if !running {
+			printError("no server answers on port %s, the memory data store is already empty", opts.port)
+			return
+		}
+
+		devUseServer(opts.port)
+
+		n, err := devClearDocuments()
+		if err != nil {
+			printError("unable to reset the server: %v", err)
+			return
+		}
+
+		printSuccess("%s document(s) deleted", clbold(n))
+	},
+}
+
+func init() {
+	serverCmd.AddCommand(serverResetCmd)
+}
@@ -0,0 +1,327 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/staticbackendhq/backend-go"
+)
+
+// devSeedFile is a file of documents to insert in a repository.
+type devSeedFile struct {
+	repo string
+	path string
+}
+
+// seedDevServer loads the fixtures of dir into a running dev server:
+//
+//	db/<repo>.json                array of documents
+//	db/<repo>.ndjson              one document per line
+//	users.csv                     email, password, role as for "users import",
+//	                              without password the admin's password is used
+//	functions/<name>.js           function with the web trigger
+//	functions/<topic>/<name>.js   function triggered by topic
+//	tasks.yml                     tasks as for "task apply"
+//
+// Repositories, users, functions and tasks that already exist are left
+// untouched, making it safe to seed a server started with --persist-data.
+func seedDevServer(port, dir string) {
+	devUseServer(port)
+
+	authToken, err := backend.Login(devAdminEmail, devAdminPassword)
+	if err != nil {
+		printError("seed: unable to sign in as %s: %v", devAdminEmail, err)
+		return
+	}
+
+	steps := []struct {
+		name string
+		fn   func() (int, error)
+	}{
+		{"document(s)", func() (int, error) { return devSeedDocuments(authToken, dir) }},
+		{"user(s)", func() (int, error) { return devSeedUsers(authToken, dir) }},
+		{"function(s)", func() (int, error) { return devSeedFunctions(dir) }},
+		{"task(s)", func() (int, error) { return devSeedTasks(dir) }},
+	}
+
+	var summary []string
+	failed := false
+	for _, step := range steps {
+		n, err := step.fn()
+		if err != nil {
+			printError("seed: %v", err)
+			failed = true
+		}
+		summary = append(summary, fmt.Sprintf("%d %s", n, step.name))
+	}
+
+	if failed {
+		printWarning("seed from %s partially loaded: %s", dir, strings.Join(summary, ", "))
+		return
+	}
+
+	printSuccess("seed from %s loaded: %s", dir, strings.Join(summary, ", "))
+}
+
+func devSeedDocuments(authToken, dir string) (int, error) {
+	files, err := devSeedDBFiles(filepath.Join(dir, "db"))
+	if err != nil || len(files) == 0 {
+		return 0, err
+	}
+
+	repos, err := backend.SudoListRepositories(devRootToken)
+	if err != nil {
+		return 0, fmt.Errorf("unable to list repositories: %w", err)
+	}
+
+	existing := map[string]bool{}
+	for _, repo := range repos {
+		existing[repo] = true
+	}
+
+	count := 0
+	for _, f := range files {
+		if existing[f.repo] {
+			fmt.Printf("seed: repository %s already exists, skipping %s\n", f.repo, f.path)
+			continue
+		}
+
+		docs, err := devSeedReadDocs(f.path)
+		if err != nil {
+			return count, fmt.Errorf("%s: %w", f.path, err)
+		}
+
+		for i, doc := range docs {
+			var created map[string]any
+			if err := backend.Create(authToken, f.repo, doc, &created); err != nil {
+				return count, fmt.Errorf("%s: document #%d: %w", f.path, i+1, err)
+			}
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// devSeedDBFiles returns the .json and .ndjson files of dir sorted by name,
+// a missing dir has no files.
+func devSeedDBFiles(dir string) ([]devSeedFile, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var files []devSeedFile
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		ext := filepath.Ext(e.Name())
+		if ext != ".json" && ext != ".ndjson" {
+			continue
+		}
+
+		files = append(files, devSeedFile{
+			repo: strings.TrimSuffix(e.Name(), ext),
+			path: filepath.Join(dir, e.Name()),
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	return files, nil
+}
+
+// devSeedReadDocs reads a JSON array of documents, or one document per line
+// for .ndjson files.
+func devSeedReadDocs(path string) ([]map[string]any, error) {
+	var docs []map[string]any
+
+	if filepath.Ext(path) == ".ndjson" {
+		err := readNDJSON(path, func(line []byte) error {
+			var doc map[string]any
+			if err := json.Unmarshal(line, &doc); err != nil {
+				return err
+			}
+			docs = append(docs, doc)
+			return nil
+		})
+		return docs, err
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, &docs); err != nil {
+		return nil, fmt.Errorf("expected an array of documents: %w", err)
+	}
+	return docs, nil
+}
+
+func devSeedUsers(authToken, dir string) (int, error) {
+	f, err := os.Open(filepath.Join(dir, "users.csv"))
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	rows, err := usersImportParse(f)
+	if err != nil {
+		return 0, fmt.Errorf("users.csv: %w", err)
+	}
+
+	// a generated password would never be shown, use a known dev password
+	for _, row := range rows {
+		if len(row.Password) == 0 {
+			row.Password = devAdminPassword
+		}
+	}
+
+	me, err := backend.Me(authToken)
+	if err != nil {
+		return 0, err
+	}
+
+	rows = usersImportRun(rows, 1, 0, func(row *usersImportRow) error {
+		return usersImportOne(authToken, devRootToken, me.AccountID, row)
+	})
+
+	count := 0
+	for _, row := range rows {
+		switch row.Status {
+		case usersImportCreated:
+			count++
+		case usersImportFailed:
+			err = fmt.Errorf("users.csv line %d (%s): %s", row.Line, row.Email, row.Err)
+		}
+	}
+
+	return count, err
+}
+
+func devSeedFunctions(dir string) (int, error) {
+	fns, err := devSeedReadFunctions(filepath.Join(dir, "functions"))
+	if err != nil || len(fns) == 0 {
+		return 0, err
+	}
+
+	current, err := backend.ListFunctions(devRootToken)
+	if err != nil {
+		return 0, fmt.Errorf("unable to list functions: %w", err)
+	}
+
+	existing := map[string]bool{}
+	for _, fn := range current {
+		existing[fn.FunctionName] = true
+	}
+
+	count := 0
+	for _, fn := range fns {
+		if existing[fn.FunctionName] {
+			fmt.Printf("seed: function %s already exists, skipping\n", fn.FunctionName)
+			continue
+		}
+
+		if err := backend.AddFunction(devRootToken, fn); err != nil {
+			return count, fmt.Errorf("function %s: %w", fn.FunctionName, err)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// devSeedReadFunctions reads the .js files of dir, files at the root use
+// the web trigger and files in a sub directory use its name as trigger.
+func devSeedReadFunctions(dir string) ([]backend.Function, error) {
+	var fns []backend.Function
+	seen := map[string]string{}
+
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+
+		if d.IsDir() || filepath.Ext(path) != ".js" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		trigger := "web"
+		if parent := filepath.Dir(rel); parent != "." {
+			if strings.ContainsRune(parent, filepath.Separator) {
+				return fmt.Errorf("%s: functions may only be nested one directory deep", path)
+			}
+			trigger = parent
+		}
+
+		name := strings.TrimSuffix(d.Name(), ".js")
+		if other, ok := seen[name]; ok {
+			return fmt.Errorf("function %s is defined by %s and %s", name, other, path)
+		}
+		seen[name] = path
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		fns = append(fns, backend.Function{
+			FunctionName: name,
+			TriggerTopic: trigger,
+			Code:         string(b),
+		})
+		return nil
+	})
+
+	return fns, err
+}
+
+func devSeedTasks(dir string) (int, error) {
+	path := filepath.Join(dir, "tasks.yml")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return 0, nil
+	}
+
+	desired, err := taskReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("tasks.yml: %w", err)
+	}
+
+	current, err := taskList(devRootToken)
+	if err != nil {
+		return 0, fmt.Errorf("unable to list tasks: %w", err)
+	}
+
+	// existing tasks are kept as is, only the missing ones are created
+	count := 0
+	for _, c := range taskPlan(desired, current, false) {
+		if c.action != taskActionCreate {
+			continue
+		}
+
+		if _, err := taskAdd(devRootToken, c.task); err != nil {
+			return count, fmt.Errorf("task %s: %w", c.name, err)
+		}
+		count++
+	}
+
+	return count, nil
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDevSeedDocuments(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "tasks.json"), `[{"title": "a"}, {"title": "b"}]`)
+	writeTestFile(t, filepath.Join(dir, "events.ndjson"), "{\"kind\": \"click\"}\n\n{\"kind\": \"view\"}\n")
+	writeTestFile(t, filepath.Join(dir, "README.md"), "ignored")
+
+	files, err := devSeedDBFiles(dir)
+	if err != nil {
+		t.Fatalf("devSeedDBFiles returned error: %v", err)
+	}
+	if len(files) != 2 || files[0].repo != "events" || files[1].repo != "tasks" {
+		t.Fatalf("devSeedDBFiles = %+v, want events and tasks", files)
+	}
+
+	for _, f := range files {
+		docs, err := devSeedReadDocs(f.path)
+		if err != nil {
+			t.Fatalf("devSeedReadDocs(%s) returned error: %v", f.path, err)
+		}
+		if len(docs) != 2 {
+			t.Fatalf("devSeedReadDocs(%s) read %d documents, want 2", f.path, len(docs))
+		}
+	}
+
+	if files, err := devSeedDBFiles(filepath.Join(dir, "missing")); err != nil || len(files) != 0 {
+		t.Fatalf("devSeedDBFiles on a missing dir = %v, %v", files, err)
+	}
+
+	writeTestFile(t, filepath.Join(dir, "bad.json"), `{"title": "not an array"}`)
+	if _, err := devSeedReadDocs(filepath.Join(dir, "bad.json")); err == nil {
+		t.Fatal("devSeedReadDocs returned nil error for a single object")
+	}
+}
+
+func TestDevSeedReadFunctions(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "hello.js"), "function handle() {}")
+	writeTestFile(t, filepath.Join(dir, "user_created", "welcome.js"), "function handle() {}")
+	writeTestFile(t, filepath.Join(dir, "notes.txt"), "ignored")
+
+	fns, err := devSeedReadFunctions(dir)
+	if err != nil {
+		t.Fatalf("devSeedReadFunctions returned error: %v", err)
+	}
+
+	triggers := map[string]string{}
+	for _, fn := range fns {
+		triggers[fn.FunctionName] = fn.TriggerTopic
+	}
+
+	if len(triggers) != 2 || triggers["hello"] != "web" || triggers["welcome"] != "user_created" {
+		t.Fatalf("devSeedReadFunctions triggers = %v", triggers)
+	}
+
+	if fns, err := devSeedReadFunctions(filepath.Join(dir, "missing")); err != nil || len(fns) != 0 {
+		t.Fatalf("devSeedReadFunctions on a missing dir = %v, %v", fns, err)
+	}
+
+	writeTestFile(t, filepath.Join(dir, "other_topic", "hello.js"), "function handle() {}")
+	if _, err := devSeedReadFunctions(dir); err == nil {
+		t.Fatal("devSeedReadFunctions returned nil error for a duplicated function name")
+	}
+}
@@ -0,0 +1,577 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/staticbackendhq/backend-go"
+)
+
+const (
+	devStoreSQLite = "sqlite"
+	devStoreMemory = "mem"
+)
+
+// devSnapshot describes a saved snapshot, it's stored as snapshot.json in
+// the snapshot directory.
+type devSnapshot struct {
+	Name    string         `json:"name"`
+	Store   string         `json:"store"`
+	Created time.Time      `json:"created"`
+	Repos   map[string]int `json:"repos,omitempty"`
+}
+
+var snapshotNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+var serverSnapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save and restore the dev server data",
+	Long: fmt.Sprintf(`
+%s
+
+Snapshots checkpoint the dev server data before a destructive test.
+
+With --persist-data the sqlite database file is copied, the server must be
+stopped to restore it. This is the only complete snapshot.
+
+In memory mode the server must be running, and only the documents of the
+%s account are exported and imported via the API. Restored documents
+get new ids, so documents referencing others by id no longer match. Users,
+functions, tasks and files are not saved: the save is refused when the
+server has users besides %s, functions, tasks or documents of other
+accounts.
+
+$> backend server snapshot save before-migration
+$> backend server snapshot restore before-migration
+
+Snapshots are saved in the snapshots directory of %s.
+	`,
+		clbold("Dev server snapshots"),
+		clbold(devAdminEmail),
+		devAdminEmail,
+		clbold(defaultDevDataDir),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(cmd.Long)
+		if err := cmd.Usage(); err != nil {
+			fmt.Println(err)
+		}
+	},
+}
+
+var serverSnapshotSaveCmd = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Save the dev server data",
+	Long: fmt.Sprintf(`
+%s
+
+In memory mode the documents are saved as db/<repo>.ndjson files, the
+snapshot directory may also be used with "backend server --seed". Only the
+documents are saved, the save fails when the server has users besides
+admin@dev.com, functions, tasks or documents of other accounts, use
+--persist-data to snapshot them.
+	`,
+		clbold("Save a snapshot"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		opts, name, ok := serverSnapshotArgs(args)
+		if !ok {
+			return
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+
+		dest := devSnapshotPath(name)
+		if _, err := os.Stat(dest); err == nil && !force {
+			printError("snapshot %s already exists, use --force to replace it", name)
+			return
+		}
+
+		snap, err := devSnapshotSave(opts, name, dest)
+		if err != nil {
+			printError("unable to save the snapshot: %v", err)
+			return
+		}
+
+		printSuccess("snapshot %s saved (%s)", clbold(snap.Name), snap.summary())
+	},
+}
+
+var serverSnapshotRestoreCmd = &cobra.Command{
+	Use:   "restore <name>",
+	Short: "Replace the dev server data with a snapshot",
+	Long: fmt.Sprintf(`
+%s
+
+In memory mode, every document is deleted before the snapshot documents are
+created. They are created in the admin@dev.com account and get new ids,
+references between documents by id are not updated.
+	`,
+		clbold("Restore a snapshot"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		opts, name, ok := serverSnapshotArgs(args)
+		if !ok {
+			return
+		}
+
+		snap, err := devSnapshotRead(devSnapshotPath(name))
+		if errors.Is(err, os.ErrNotExist) {
+			printError("snapshot %s does not exist, see \"backend server snapshot list\"", name)
+			return
+		} else if err != nil {
+			printError("unable to read snapshot %s: %v", name, err)
+			return
+		}
+
+		if store := opts.store(); snap.Store != store {
+			printError("snapshot %s was saved from the %s data store, the server uses %s", name, snap.Store, store)
+			return
+		}
+
+		if err := devSnapshotRestore(opts, snap, devSnapshotPath(name)); err != nil {
+			printError("unable to restore the snapshot: %v", err)
+			return
+		}
+
+		printSuccess("snapshot %s restored (%s)", clbold(snap.Name), snap.summary())
+	},
+}
+
+var serverSnapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the saved snapshots",
+	Run: func(cmd *cobra.Command, args []string) {
+		snaps, err := devSnapshotList()
+		if err != nil {
+			printError("unable to list snapshots: %v", err)
+			return
+		}
+
+		fmt.Printf("%s snapshot(s)\n\n", clbold(len(snaps)))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
+		fmt.Fprintf(w, "NAME\tSTORE\tCREATED\tCONTENT\n")
+		for _, snap := range snaps {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+				snap.Name,
+				snap.Store,
+				snap.Created.Format("2006/01/02 15:04:05"),
+				snap.summary(),
+			)
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	serverCmd.AddCommand(serverSnapshotCmd)
+
+	serverSnapshotCmd.AddCommand(serverSnapshotSaveCmd)
+	serverSnapshotCmd.AddCommand(serverSnapshotRestoreCmd)
+	serverSnapshotCmd.AddCommand(serverSnapshotListCmd)
+
+	serverSnapshotSaveCmd.Flags().Bool("force", false, "replace an existing snapshot with the same name")
+}
+
+func serverSnapshotArgs(args []string) (devServerOptions, string, bool) {
+	if len(args) != 1 {
+		printError("argument mismatch: one snapshot name should be specified")
+		return devServerOptions{}, "", false
+	}
+
+	if !snapshotNameRe.MatchString(args[0]) {
+		printError("invalid snapshot name %q: use letters, digits, dots, dashes and underscores", args[0])
+		return devServerOptions{}, "", false
+	}
+
+	opts, err := getDevServerOptions()
+	if err != nil {
+		printError("invalid server configuration: %v", err)
+		return opts, "", false
+	}
+
+	return opts, args[0], true
+}
+
+func (opts devServerOptions) store() string {
+	if opts.persistData {
+		return devStoreSQLite
+	}
+	return devStoreMemory
+}
+
+func (snap devSnapshot) summary() string {
+	if snap.Store == devStoreSQLite {
+		return "database file"
+	}
+
+	docs := 0
+	for _, n := range snap.Repos {
+		docs += n
+	}
+	return fmt.Sprintf("%d document(s) in %d repositories", docs, len(snap.Repos))
+}
+
+func devSnapshotPath(name string) string {
+	return filepath.Join(devDataDir(), "snapshots", name)
+}
+
+// devUseServer points the backend client to the dev server on port using
+// the dev credentials.
+func devUseServer(port string) {
+	backend.PublicKey = devPublicKey
+	backend.Region = "http://localhost:" + port
+}
+
+// devSQLiteFiles returns the database file and its write-ahead log files.
+func devSQLiteFiles(dbPath string) []string {
+	return []string{dbPath, dbPath + "-wal", dbPath + "-shm"}
+}
+
+// devSnapshotSQLitePath returns the path in the snapshot dir of one of the
+// devSQLiteFiles, independent of the database file name.
+func devSnapshotSQLitePath(dir, dbPath, file string) string {
+	return filepath.Join(dir, "sqlite"+strings.TrimPrefix(file, dbPath))
+}
+
+// devSnapshotSave writes the snapshot in a temporary directory renamed to
+// dest once complete, an existing snapshot is only replaced on success.
+func devSnapshotSave(opts devServerOptions, name, dest string) (devSnapshot, error) {
+	snap := devSnapshot{Name: name, Store: opts.store(), Created: time.Now()}
+
+	tmp := dest + ".tmp"
+	if err := os.RemoveAll(tmp); err != nil {
+		return snap, err
+	}
+	if err := os.MkdirAll(tmp, 0755); err != nil {
+		return snap, err
+	}
+	defer os.RemoveAll(tmp)
+
+	running := devServerRunning(opts.port)
+
+	switch snap.Store {
+	case devStoreSQLite:
+		if running {
+			printWarning("the server is running, writes in progress may be missing from the snapshot")
+		}
+
+		for _, src := range devSQLiteFiles(opts.dbPath) {
+			err := copyFile(src, devSnapshotSQLitePath(tmp, opts.dbPath, src))
+			if err != nil && !(os.IsNotExist(err) && src != opts.dbPath) {
+				return snap, err
+			}
+		}
+	default:
+		if !running {
+			return snap, fmt.Errorf("no server answers on port %s, the memory data store only exists while the server runs", opts.port)
+		}
+
+		devUseServer(opts.port)
+
+		accountID, err := devSnapshotCheckMemory()
+		if err != nil {
+			return snap, err
+		}
+
+		repos, err := devSnapshotExport(tmp, accountID)
+		if err != nil {
+			return snap, err
+		}
+		snap.Repos = repos
+	}
+
+	b, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return snap, err
+	}
+
+	if err := os.WriteFile(filepath.Join(tmp, "snapshot.json"), b, 0644); err != nil {
+		return snap, err
+	}
+
+	if err := os.RemoveAll(dest); err != nil {
+		return snap, err
+	}
+
+	return snap, os.Rename(tmp, dest)
+}
+
+func devSnapshotRestore(opts devServerOptions, snap devSnapshot, dir string) error {
+	running := devServerRunning(opts.port)
+
+	if snap.Store == devStoreSQLite {
+		if running {
+			return fmt.Errorf("the server is running on port %s, stop it before restoring a sqlite snapshot", opts.port)
+		}
+
+		for _, dest := range devSQLiteFiles(opts.dbPath) {
+			if err := removeIfExists(dest); err != nil {
+				return err
+			}
+
+			err := copyFile(devSnapshotSQLitePath(dir, opts.dbPath, dest), dest)
+			if err != nil && !(os.IsNotExist(err) && dest != opts.dbPath) {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if !running {
+		return fmt.Errorf("no server answers on port %s, start it before restoring a memory snapshot", opts.port)
+	}
+
+	devUseServer(opts.port)
+
+	if _, err := devClearDocuments(); err != nil {
+		return err
+	}
+
+	return devSnapshotImport(dir)
+}
+
+func devSnapshotRead(dir string) (devSnapshot, error) {
+	var snap devSnapshot
+
+	b, err := os.ReadFile(filepath.Join(dir, "snapshot.json"))
+	if err != nil {
+		return snap, err
+	}
+
+	err = json.Unmarshal(b, &snap)
+	return snap, err
+}
+
+// devSnapshotList returns the snapshots sorted by creation time.
+func devSnapshotList() ([]devSnapshot, error) {
+	root := filepath.Join(devDataDir(), "snapshots")
+
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var snaps []devSnapshot
+	for _, e := range entries {
+		if !e.IsDir() || !snapshotNameRe.MatchString(e.Name()) {
+			continue
+		}
+
+		snap, err := devSnapshotRead(filepath.Join(root, e.Name()))
+		if err != nil {
+			// unfinished saves have no snapshot.json
+			continue
+		}
+		snaps = append(snaps, snap)
+	}
+
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].Created.Before(snaps[j].Created) })
+	return snaps, nil
+}
+
+// devSnapshotCheckMemory returns the account of the admin, or an error when
+// the server has data a memory snapshot cannot hold.
+func devSnapshotCheckMemory() (string, error) {
+	tok, err := backend.Login(devAdminEmail, devAdminPassword)
+	if err != nil {
+		return "", fmt.Errorf("unable to sign in as %s: %w", devAdminEmail, err)
+	}
+
+	users, err := backend.Users(tok)
+	if err != nil {
+		return "", fmt.Errorf("unable to list users: %w", err)
+	}
+
+	var accountID string
+	others := 0
+	for _, u := range users {
+		if u.Email == devAdminEmail {
+			accountID = u.AccountID
+		} else {
+			others++
+		}
+	}
+
+	functions, err := backend.ListFunctions(devRootToken)
+	if err != nil {
+		return "", fmt.Errorf("unable to list functions: %w", err)
+	}
+
+	tasks, err := taskList(devRootToken)
+	if err != nil {
+		return "", fmt.Errorf("unable to list tasks: %w", err)
+	}
+
+	return accountID, devSnapshotUnsupported(others, len(functions), len(tasks))
+}
+
+// devSnapshotUnsupported returns an error listing the data a memory snapshot
+// would lose, nil when there is none.
+func devSnapshotUnsupported(users, functions, tasks int) error {
+	var lost []string
+	if users > 0 {
+		lost = append(lost, fmt.Sprintf("%d user(s) besides %s", users, devAdminEmail))
+	}
+	if functions > 0 {
+		lost = append(lost, fmt.Sprintf("%d function(s)", functions))
+	}
+	if tasks > 0 {
+		lost = append(lost, fmt.Sprintf("%d task(s)", tasks))
+	}
+
+	if len(lost) == 0 {
+		return nil
+	}
+	return fmt.Errorf("memory snapshots only hold documents, the server also has %s: use --persist-data to snapshot them", strings.Join(lost, ", "))
+}
+
+// devSnapshotExport saves the documents of every repository as
+// db/<repo>.ndjson files in dir. The documents must belong to accountID
+// since they are restored in the admin account.
+func devSnapshotExport(dir, accountID string) (map[string]int, error) {
+	repos, err := backend.SudoListRepositories(devRootToken)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list repositories: %w", err)
+	}
+
+	counts := map[string]int{}
+	for _, repo := range repos {
+		docs, err := devListAllDocuments(repo)
+		if err != nil {
+			return nil, fmt.Errorf("unable to export %s: %w", repo, err)
+		}
+
+		for _, doc := range docs {
+			if owner, _ := doc["accountId"].(string); len(accountID) > 0 && owner != accountID {
+				return nil, fmt.Errorf("%s has documents of other accounts than %s, memory snapshots only hold the admin account documents: use --persist-data to snapshot them", repo, devAdminEmail)
+			}
+		}
+
+		path := filepath.Join(dir, "db", repo+".ndjson")
+		for _, doc := range docs {
+			if err := appendNDJSON(path, doc); err != nil {
+				return nil, err
+			}
+		}
+
+		counts[repo] = len(docs)
+	}
+
+	return counts, nil
+}
+
+func devSnapshotImport(dir string) error {
+	files, err := devSeedDBFiles(filepath.Join(dir, "db"))
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		docs, err := devSeedReadDocs(f.path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.path, err)
+		}
+
+		for _, doc := range docs {
+			// ids are assigned by the server
+			delete(doc, "id")
+			delete(doc, "accountId")
+
+			var created map[string]any
+			if err := backend.SudoCreate(devRootToken, f.repo, doc, &created); err != nil {
+				return fmt.Errorf("unable to import into %s: %w", f.repo, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// devClearDocuments deletes every document of every repository and returns
+// the number of deleted documents.
+func devClearDocuments() (int, error) {
+	repos, err := backend.SudoListRepositories(devRootToken)
+	if err != nil {
+		return 0, fmt.Errorf("unable to list repositories: %w", err)
+	}
+
+	count := 0
+	for _, repo := range repos {
+		docs, err := devListAllDocuments(repo)
+		if err != nil {
+			return count, fmt.Errorf("unable to list %s: %w", repo, err)
+		}
+
+		for _, doc := range docs {
+			id, _ := doc["id"].(string)
+			if len(id) == 0 {
+				continue
+			}
+
+			if err := backend.SudoDelete(devRootToken, repo, id); err != nil {
+				return count, fmt.Errorf("unable to delete %s/%s: %w", repo, id, err)
+			}
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func devListAllDocuments(repo string) ([]map[string]any, error) {
+	var all []map[string]any
+
+	lp := &backend.ListParams{Page: 1, Size: 100}
+	for {
+		var docs []map[string]any
+		_, err := backend.SudoList(devRootToken, repo, &docs, lp)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, docs...)
+		if len(docs) < lp.Size {
+			return all, nil
+		}
+		lp.Page++
+	}
+}
+
+// copyFile copies src to dest, creating the dest directory.
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
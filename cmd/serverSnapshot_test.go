@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestSnapshotName(t *testing.T) {
+	valid := []string{"before-migration", "v1.2", "run_3"}
+	for _, name := range valid {
+		if !snapshotNameRe.MatchString(name) {
+			t.Fatalf("snapshot name %q should be valid", name)
+		}
+	}
+
+	invalid := []string{"", "../etc", "a/b", ".hidden", "with space"}
+	for _, name := range invalid {
+		if snapshotNameRe.MatchString(name) {
+			t.Fatalf("snapshot name %q should be invalid", name)
+		}
+	}
+}
+
+func TestDevSnapshotList(t *testing.T) {
+	dir := t.TempDir()
+	viper.Set("server.dataDir", dir)
+	defer viper.Set("server.dataDir", "")
+
+	snaps := []devSnapshot{
+		{Name: "second", Store: devStoreMemory, Created: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), Repos: map[string]int{"tasks": 2, "users": 1}},
+		{Name: "first", Store: devStoreSQLite, Created: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, snap := range snaps {
+		b, err := json.Marshal(snap)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeTestFile(t, filepath.Join(devSnapshotPath(snap.Name), "snapshot.json"), string(b))
+	}
+
+	// an unfinished save is ignored
+	if err := os.MkdirAll(devSnapshotPath("partial"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := devSnapshotList()
+	if err != nil {
+		t.Fatalf("devSnapshotList returned error: %v", err)
+	}
+
+	if len(list) != 2 || list[0].Name != "first" || list[1].Name != "second" {
+		t.Fatalf("devSnapshotList = %+v, want first and second", list)
+	}
+
+	if got := list[1].summary(); got != "3 document(s) in 2 repositories" {
+		t.Fatalf("summary = %q", got)
+	}
+}
+
+func TestDevSnapshotSQLitePath(t *testing.T) {
+	got := devSnapshotSQLitePath("snap", "data/dev.db", "data/dev.db-wal")
+	if want := filepath.Join("snap", "sqlite-wal"); got != want {
+		t.Fatalf("devSnapshotSQLitePath = %q, want %q", got, want)
+	}
+}
+
+func TestDevSnapshotUnsupported(t *testing.T) {
+	if err := devSnapshotUnsupported(0, 0, 0); err != nil {
+		t.Fatalf("devSnapshotUnsupported returned %v for documents only", err)
+	}
+
+	err := devSnapshotUnsupported(2, 0, 1)
+	if err == nil {
+		t.Fatal("devSnapshotUnsupported returned nil with users and tasks")
+	}
+	for _, want := range []string{"2 user(s)", "1 task(s)", "--persist-data"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("devSnapshotUnsupported = %q, want it to contain %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "function") {
+		t.Fatalf("devSnapshotUnsupported = %q, want no functions", err)
+	}
+}
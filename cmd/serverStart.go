@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// devStateEnv tells a detached server where its state file is, the server
+// marks it ready once it accepts requests.
+const devStateEnv = "BACKEND_SERVER_STATE_FILE"
+
+// devServerState is saved in the data directory while a detached server
+// runs.
+type devServerState struct {
+	PID     int       `json:"pid"`
+	Port    string    `json:"port"`
+	URL     string    `json:"url"`
+	DataDir string    `json:"dataDir"`
+	LogFile string    `json:"logFile"`
+	Store   string    `json:"store"`
+	Started time.Time `json:"started"`
+	Ready   bool      `json:"ready"`
+}
+
+var serverStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Starts the dev server, in the background with --detach",
+	Long: fmt.Sprintf(`
+%s
+
+Without --detach this is the same as "backend server".
+
+With --detach the server runs in the background and the command returns once
+it is ready. Its output goes to server.log and its PID, port and data
+directory are saved in server.json, both in the data directory.
+
+$> backend server start --detach --seed ./seed
+$> npm test
+$> backend server stop
+
+Use "backend server logs" to view its output and "backend server status" to
+check its health.
+	`,
+		clbold("Start the dev server"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		detach, _ := cmd.Flags().GetBool("detach")
+		if !detach {
+			runDevServer()
+			return
+		}
+
+		opts, err := getDevServerOptions()
+		if err != nil {
+			printError("invalid server configuration: %v", err)
+			os.Exit(1)
+		}
+
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		state, err := startDetachedDevServer(opts, timeout)
+		if err != nil {
+			printError("%v", err)
+			os.Exit(1)
+		}
+
+		printSuccess("dev server started at %s (pid %d)", clbold(state.URL), state.PID)
+		fmt.Printf("\nlogs:  backend server logs\nstop:  backend server stop\n")
+	},
+}
+
+func init() {
+	serverCmd.AddCommand(serverStartCmd)
+
+	serverStartCmd.Flags().Bool("detach", false, "run the server in the background")
+	serverStartCmd.Flags().Duration("timeout", devReadyTimeout, "maximum time to wait for a detached server to be ready")
+}
+
+func devStatePath() string {
+	return filepath.Join(devDataDir(), "server.json")
+}
+
+func devLogPath() string {
+	return filepath.Join(devDataDir(), "server.log")
+}
+
+func readDevServerState(path string) (devServerState, error) {
+	var state devServerState
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return state, err
+	}
+
+	err = json.Unmarshal(b, &state)
+	return state, err
+}
+
+func writeDevServerState(path string, state devServerState) error {
+	b, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// markDevServerReady flags the state file of the current process as ready,
+// waiting for the parent process to write it.
+func markDevServerReady(path string) error {
+	var state devServerState
+	err := devRetry(5*time.Second, func() error {
+		var err error
+		if state, err = readDevServerState(path); err != nil {
+			return err
+		} else if state.PID != os.Getpid() {
+			return fmt.Errorf("%s belongs to process %d", path, state.PID)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	state.Ready = true
+	return writeDevServerState(path, state)
+}
+
+// runningDevServerState returns the state of the detached server, a state
+// file left by a server that's no longer running is removed.
+func runningDevServerState() (devServerState, bool, error) {
+	path := devStatePath()
+
+	state, err := readDevServerState(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, false, nil
+	} else if err != nil {
+		return state, false, err
+	}
+
+	if devProcessAlive(state.PID) {
+		return state, true, nil
+	}
+
+	return state, false, removeIfExists(path)
+}
+
+// detachedArgs returns the command line arguments without --detach.
+func detachedArgs(args []string) []string {
+	var out []string
+	for _, arg := range args {
+		if arg == "--detach" || strings.HasPrefix(arg, "--detach=") {
+			continue
+		}
+		out = append(out, arg)
+	}
+	return out
+}
+
+func startDetachedDevServer(opts devServerOptions, timeout time.Duration) (devServerState, error) {
+	state, running, err := runningDevServerState()
+	if err != nil {
+		return state, fmt.Errorf("unable to read the state file: %w", err)
+	} else if running {
+		return state, fmt.Errorf("a dev server is already running at %s (pid %d), use \"backend server stop\"", state.URL, state.PID)
+	}
+
+	if devServerRunning(opts.port) {
+		return state, fmt.Errorf("another server already listens on port %s", opts.port)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return state, fmt.Errorf("unable to find the backend executable: %w", err)
+	}
+
+	dataDir, err := filepath.Abs(devDataDir())
+	if err != nil {
+		return state, err
+	}
+
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return state, err
+	}
+
+	logPath := filepath.Join(dataDir, "server.log")
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return state, fmt.Errorf("unable to create the log file: %w", err)
+	}
+	defer logFile.Close()
+
+	statePath := filepath.Join(dataDir, "server.json")
+
+	cmd := exec.Command(exe, detachedArgs(os.Args[1:])...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.Env = append(os.Environ(), devStateEnv+"="+statePath)
+	cmd.SysProcAttr = devDetachAttr()
+
+	if err := cmd.Start(); err != nil {
+		return state, fmt.Errorf("unable to start the server: %w", err)
+	}
+
+	state = devServerState{
+		PID:     cmd.Process.Pid,
+		Port:    opts.port,
+		URL:     "http://localhost:" + opts.port,
+		DataDir: dataDir,
+		LogFile: logPath,
+		Store:   opts.store(),
+		Started: time.Now(),
+	}
+
+	if err := writeDevServerState(statePath, state); err != nil {
+		cmd.Process.Kill()
+		return state, fmt.Errorf("unable to write the state file: %w", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	err = devRetry(timeout, func() error {
+		select {
+		case err := <-exited:
+			exited <- err
+			return nil
+		default:
+		}
+
+		s, err := readDevServerState(statePath)
+		if err != nil {
+			return err
+		} else if !s.Ready {
+			return errors.New("the server is starting")
+		}
+		return nil
+	})
+
+	select {
+	case werr := <-exited:
+		removeIfExists(statePath)
+		if werr == nil {
+			return state, fmt.Errorf("the server exited, see %s", logPath)
+		}
+		return state, fmt.Errorf("the server exited (%v), see %s", werr, logPath)
+	default:
+	}
+
+	if err != nil {
+		cmd.Process.Kill()
+		removeIfExists(statePath)
+		return state, fmt.Errorf("%v, see %s", err, logPath)
+	}
+
+	state.Ready = true
+	return state, nil
+}
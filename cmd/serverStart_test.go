@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestDetachedArgs(t *testing.T) {
+	args := []string{"server", "start", "--detach", "--port", "9000", "--detach=true", "--seed", "./seed"}
+	want := []string{"server", "start", "--port", "9000", "--seed", "./seed"}
+
+	if got := detachedArgs(args); !reflect.DeepEqual(got, want) {
+		t.Fatalf("detachedArgs = %v, want %v", got, want)
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	input := "one\ntwo\nthree\nfour\n"
+
+	got, err := lastLines(strings.NewReader(input), 2)
+	if err != nil || !reflect.DeepEqual(got, []string{"three", "four"}) {
+		t.Fatalf("lastLines(2) = %v, %v", got, err)
+	}
+
+	got, err = lastLines(strings.NewReader(input), 0)
+	if err != nil || len(got) != 4 {
+		t.Fatalf("lastLines(0) = %v, %v", got, err)
+	}
+}
+
+func TestRunningDevServerState(t *testing.T) {
+	dir := t.TempDir()
+	viper.Set("server.dataDir", dir)
+	defer viper.Set("server.dataDir", "")
+
+	if _, running, err := runningDevServerState(); err != nil || running {
+		t.Fatalf("runningDevServerState without state file = %v, %v", running, err)
+	}
+
+	state := devServerState{PID: os.Getpid(), Port: "9000"}
+	if err := writeDevServerState(devStatePath(), state); err != nil {
+		t.Fatal(err)
+	}
+
+	got, running, err := runningDevServerState()
+	if err != nil || !running || got.Port != "9000" {
+		t.Fatalf("runningDevServerState = %+v, %v, %v", got, running, err)
+	}
+
+	// a state file left by a process that is gone is removed
+	state.PID = 0
+	if err := writeDevServerState(devStatePath(), state); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, running, err := runningDevServerState(); err != nil || running {
+		t.Fatalf("runningDevServerState with a stale file = %v, %v", running, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "server.json")); !os.IsNotExist(err) {
+		t.Fatalf("stale state file was not removed: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var serverStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Check the health of a running dev server",
+	Long: fmt.Sprintf(`
+%s
+
+Checks that the dev server on --port accepts requests, has loaded its seed
+and that the %s account can sign in. The exit code is 1 when the server is
+not ready, making it usable from scripts:
+
+$> backend server --seed ./seed &
+$> backend server status --wait-ready --timeout 1m
+$> npm test
+
+Use --json to print the URL and dev credentials as JSON.
+	`,
+		clbold("Dev server status"),
+		clbold(devAdminEmail),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		opts, err := getDevServerOptions()
+		if err != nil {
+			printError("invalid server configuration: %v", err)
+			os.Exit(1)
+		}
+
+		wait, _ := cmd.Flags().GetBool("wait-ready")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		info := newDevReadyInfo(opts.port)
+
+		start := time.Now()
+		check := func() error {
+			if err := devProbeReady(info.URL); err != nil {
+				return err
+			}
+			return devAdminReady(opts.port)
+		}
+
+		if wait {
+			err = devRetry(timeout, check)
+		} else {
+			err = check()
+		}
+
+		state, detached, _ := runningDevServerState()
+
+		if asJSON {
+			status := map[string]any{"ready": err == nil}
+			if detached {
+				status["detached"] = state
+			}
+			if err != nil {
+				status["error"] = err.Error()
+			} else {
+				info.PID = 0
+				status["server"] = info
+			}
+
+			b, _ := json.MarshalIndent(status, "", "  ")
+			fmt.Println(string(b))
+		} else if err != nil {
+			printError("the dev server at %s is not ready: %v", info.URL, err)
+		} else {
+			printSuccess("the dev server at %s is ready (%v)", clbold(info.URL), time.Since(start).Round(time.Millisecond))
+			fmt.Printf("\npublic key: %s\nroot token: %s\nadmin:      %s / %s\ninbox:      %s\n",
+				info.PublicKey, info.RootToken, info.Email, info.Password, info.Inbox)
+		}
+
+		if detached && !asJSON {
+			fmt.Printf("\ndetached:   pid %d, up %v\nlogs:       %s\n",
+				state.PID, time.Since(state.Started).Round(time.Second), state.LogFile)
+		}
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	serverCmd.AddCommand(serverStatusCmd)
+
+	serverStatusCmd.Flags().Bool("wait-ready", false, "wait for the server to be ready instead of checking once")
+	serverStatusCmd.Flags().Duration("timeout", devReadyTimeout, "maximum time to wait with --wait-ready")
+	serverStatusCmd.Flags().Bool("json", false, "print the status as JSON")
+}
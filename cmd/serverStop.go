@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var serverStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stops the dev server started with --detach",
+	Long: fmt.Sprintf(`
+%s
+
+Asks the detached server to terminate and waits for it to exit, it is killed
+if still running after --timeout.
+	`,
+		clbold("Stop the dev server"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		state, running, err := runningDevServerState()
+		if err != nil {
+			printError("unable to read the state file: %v", err)
+			os.Exit(1)
+		} else if !running {
+			printWarning("no detached dev server is running")
+			return
+		}
+
+		if err := devStopProcess(state.PID); err != nil {
+			printError("unable to stop process %d: %v", state.PID, err)
+			os.Exit(1)
+		}
+
+		err = devRetry(timeout, func() error {
+			if devProcessAlive(state.PID) {
+				return errors.New("the server is still running")
+			}
+			return nil
+		})
+		if err != nil {
+			printWarning("the server did not exit after %v, killing it", timeout)
+			if p, err := os.FindProcess(state.PID); err == nil {
+				p.Kill()
+			}
+		}
+
+		if err := removeIfExists(devStatePath()); err != nil {
+			printError("unable to remove the state file: %v", err)
+		}
+
+		printSuccess("dev server stopped (pid %d, up %v)", state.PID, time.Since(state.Started).Round(time.Second))
+	},
+}
+
+func init() {
+	serverCmd.AddCommand(serverStopCmd)
+
+	serverStopCmd.Flags().Duration("timeout", 10*time.Second, "maximum time to wait for the server to exit")
+}
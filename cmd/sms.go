@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// smsCmd represents the sms command
+var smsCmd = &cobra.Command{
+	Use:   "sms",
+	Short: "Send text messages.",
+	Long: fmt.Sprintf(`
+%s
+
+Send text messages via Twilio. You'll need a rootToken and your Twilio
+credentials in your config file:
+
+	twilioAccountSID: your-account-sid
+	twilioAuthToken: your-auth-token
+	twilioFromNumber: "+15557654321"
+
+When working with the dev server, messages are captured locally instead, view
+them with "backend sms inbox".
+	`,
+		clbold("Send text messages"),
+	),
+}
+
+func init() {
+	rootCmd.AddCommand(smsCmd)
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// smsInboxCmd lists text messages captured by the dev server
+var smsInboxCmd = &cobra.Command{
+	Use:   "inbox",
+	Short: "List text messages captured by the dev server",
+	Long: fmt.Sprintf(`
+%s
+
+When running, "backend server" captures every text message sent via the SMS
+API instead of calling Twilio. Captured messages are saved in the %s
+directory of the folder where the server was started, or the server.dataDir
+config entry.
+	`,
+		clbold("Dev server SMS inbox"),
+		clbold(defaultDevDataDir),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		msgs, err := newDevSMSBox().list()
+		if err != nil {
+			printError("error reading inbox: %v", err)
+			return
+		}
+
+		fmt.Printf("%s message(s)\n\n", clbold(len(msgs)))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
+		fmt.Fprintf(w, "ID\tRECEIVED\tFROM\tTO\tBODY\n")
+		for i := len(msgs) - 1; i >= 0; i-- {
+			m := msgs[i]
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n",
+				m.ID,
+				m.Received.Format("2006/01/02 15:04:05"),
+				m.From,
+				m.To,
+				m.Body,
+			)
+		}
+		w.Flush()
+	},
+}
+
+var smsInboxClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete all captured text messages",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := newDevSMSBox().clear(); err != nil {
+			printError("error clearing inbox: %v", err)
+			return
+		}
+
+		printSuccess("the inbox has been cleared")
+	},
+}
+
+func init() {
+	smsCmd.AddCommand(smsInboxCmd)
+
+	smsInboxCmd.AddCommand(smsInboxClearCmd)
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/staticbackendhq/backend-go"
+)
+
+// smsSendCmd sends a text message
+var smsSendCmd = &cobra.Command{
+	Use:   "send",
+	Short: "Send a text message",
+	Long: fmt.Sprintf(`
+%s
+
+backend sms send --to "+15551234567" --body "Your code is 1234"
+
+The Twilio account SID, auth token, and sender number are read from the
+twilioAccountSID, twilioAuthToken, and twilioFromNumber config entries, or
+the TWILIOACCOUNTSID, TWILIOAUTHTOKEN, and TWILIOFROMNUMBER environment
+variables.
+	`,
+		clbold("Send a text message"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		if setBackend() == false {
+			return
+		}
+
+		tok, ok := getRootToken()
+		if !ok {
+			return
+		}
+
+		to, err := cmd.Flags().GetString("to")
+		if err != nil || len(to) == 0 {
+			printError("missing parameter: the --to option is required")
+			return
+		}
+
+		body, err := cmd.Flags().GetString("body")
+		if err != nil || len(body) == 0 {
+			printError("missing parameter: the --body option is required")
+			return
+		}
+
+		data, ok := smsCredentials(cmd)
+		if !ok {
+			return
+		}
+
+		data.ToNumber = to
+		data.Body = body
+
+		if err := backend.SudoSendSMS(tok, data); err != nil {
+			printError("error sending your text message: %v", err)
+			return
+		}
+
+		if isLocalRegion(backend.Region) {
+			printSuccess("Text message to %s captured by the dev server", clbold(to))
+			return
+		}
+
+		printSuccess("Text message sent to %s", clbold(to))
+	},
+}
+
+func init() {
+	smsCmd.AddCommand(smsSendCmd)
+
+	smsSendCmd.Flags().String("to", "", "recipient phone number")
+	smsSendCmd.Flags().String("from", "", "sender phone number, overrides twilioFromNumber")
+	smsSendCmd.Flags().String("body", "", "message body")
+}
+
+func smsCredentials(cmd *cobra.Command) (backend.SMSData, bool) {
+	data := backend.SMSData{
+		AccountSID: cleanConfigValue(viper.GetString("twilioAccountSID")),
+		AuthToken:  cleanConfigValue(viper.GetString("twilioAuthToken")),
+		FromNumber: cleanConfigValue(viper.GetString("twilioFromNumber")),
+	}
+
+	if from, _ := cmd.Flags().GetString("from"); len(from) > 0 {
+		data.FromNumber = from
+	}
+
+	// the dev server captures messages, credentials are not needed
+	if isLocalRegion(backend.Region) {
+		if len(data.AccountSID) == 0 {
+			data.AccountSID = "dev"
+		}
+		if len(data.AuthToken) == 0 {
+			data.AuthToken = "dev"
+		}
+		if len(data.FromNumber) == 0 {
+			data.FromNumber = "+15550000000"
+		}
+		return data, true
+	}
+
+	if len(data.AccountSID) == 0 || len(data.AuthToken) == 0 {
+		printError("cannot find twilioAccountSID and twilioAuthToken in your .backend.yml config file")
+		return data, false
+	}
+
+	if len(data.FromNumber) == 0 {
+		printError("missing parameter: the --from option or twilioFromNumber config entry is required")
+		return data, false
+	}
+
+	return data, true
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/staticbackendhq/backend-go"
+)
+
+// devSMS is a text message captured by the dev server.
+type devSMS struct {
+	ID       int       `json:"id"`
+	Received time.Time `json:"received"`
+	To       string    `json:"to"`
+	From     string    `json:"from"`
+	Body     string    `json:"body"`
+}
+
+// devSMSBox stores captured text messages as NDJSON.
+type devSMSBox struct {
+	mu   sync.Mutex
+	path string
+}
+
+func newDevSMSBox() *devSMSBox {
+	return &devSMSBox{path: filepath.Join(devDataDir(), "sms.ndjson")}
+}
+
+func (sb *devSMSBox) add(data backend.SMSData) (devSMS, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	msgs, err := sb.readAll()
+	if err != nil {
+		return devSMS{}, err
+	}
+
+	// Twilio credentials are never persisted
+	m := devSMS{
+		ID:       len(msgs) + 1,
+		Received: time.Now(),
+		To:       data.ToNumber,
+		From:     data.FromNumber,
+		Body:     data.Body,
+	}
+
+	return m, appendNDJSON(sb.path, m)
+}
+
+func (sb *devSMSBox) list() ([]devSMS, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	return sb.readAll()
+}
+
+func (sb *devSMSBox) clear() error {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	return removeIfExists(sb.path)
+}
+
+func (sb *devSMSBox) readAll() ([]devSMS, error) {
+	var msgs []devSMS
+	err := readNDJSON(sb.path, func(line []byte) error {
+		var m devSMS
+		if err := json.Unmarshal(line, &m); err != nil {
+			return err
+		}
+		msgs = append(msgs, m)
+		return nil
+	})
+	return msgs, err
+}
+
+// captureHandler replaces the send SMS endpoint of the dev server so Twilio
+// is never called.
+func (sb *devSMSBox) captureHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var data backend.SMSData
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		m, err := sb.add(data)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		fmt.Printf("SMS captured #%d to %s\n", m.ID, m.To)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, "true")
+	})
+}
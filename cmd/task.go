@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -62,7 +61,10 @@ var taskAddCmd = &cobra.Command{
 Examples:
 
 backend task add --name nightly-cleanup --type function --value cleanup --interval "0 2 * * *"
-backend task add --name ping --type http --value https://example.com/hook --interval "*/15 * * * *" --meta '{"method":"POST","ct":"application/json","data":"{\"ok\":true}"}'
+backend task add --name ping --type http --value https://example.com/hook --interval "*/15 * * * *" \
+	--method POST --content-type application/json --body '{"ok":true}'
+backend task add --name report --type message --value report_ready --interval "0 8 * * 1" \
+	--channel reports --data '{"weekly":true}'
 	`,
 		clbold("Create a scheduled task"),
 	),
@@ -203,8 +205,8 @@ func addTaskFlags(cmd *cobra.Command) {
 	cmd.Flags().String("name", "", "task name")
 	cmd.Flags().String("type", "", "task type: function, message, or http")
 	cmd.Flags().String("value", "", "task value: function name, message type, or HTTP URL")
-	cmd.Flags().String("interval", "", "cron interval in UTC")
-	cmd.Flags().String("meta", "", "optional JSON metadata")
+	cmd.Flags().String("interval", "", "cron interval in UTC, ex: \"0 2 * * *\"")
+	addTaskMetaFlags(cmd)
 }
 
 func taskFromFlags(cmd *cobra.Command, task model.Task) (model.Task, bool) {
@@ -247,17 +249,18 @@ func taskFromFlags(cmd *cobra.Command, task model.Task) (model.Task, bool) {
 			printError("missing parameter: the --interval option is required")
 			return task, false
 		}
+		if _, err := parseCron(interval); err != nil {
+			printError("invalid --interval %q: %v", interval, err)
+			return task, false
+		}
 		task.Interval = interval
 	}
 
-	if cmd.Flags().Changed("meta") {
-		meta, _ := cmd.Flags().GetString("meta")
-		if len(meta) > 0 && !json.Valid([]byte(meta)) {
-			printError("invalid metadata: --meta must be valid JSON")
-			return task, false
-		}
-		task.Meta = meta
+	meta, ok := taskMetaFromFlags(cmd, task)
+	if !ok {
+		return task, false
 	}
+	task.Meta = meta
 
 	return task, true
 }
@@ -326,9 +329,7 @@ func taskPrintInfo(task model.Task) {
 	)
 	w.Flush()
 
-	if len(task.Meta) > 0 {
-		fmt.Printf("\nMETA\n%s\n", task.Meta)
-	}
+	taskPrintMeta(task)
 }
 
 func taskFormatTime(t time.Time) string {
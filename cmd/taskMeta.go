@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/staticbackendhq/core/model"
+)
+
+// taskHTTPMeta is the Meta of http tasks.
+type taskHTTPMeta struct {
+	Method      string `json:"method"`
+	ContentType string `json:"ct"`
+	Data        string `json:"data"`
+}
+
+// taskMessageMeta is the Meta of message tasks.
+type taskMessageMeta struct {
+	Channel string `json:"channel"`
+	Data    string `json:"data"`
+}
+
+var taskHTTPMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"}
+
+var (
+	taskHTTPFlags    = []string{"method", "body", "body-file", "content-type"}
+	taskMessageFlags = []string{"channel", "data"}
+)
+
+func addTaskMetaFlags(cmd *cobra.Command) {
+	cmd.Flags().String("meta", "", "optional raw JSON metadata, prefer the typed flags below")
+
+	cmd.Flags().String("method", "", "http tasks: HTTP method (default GET)")
+	cmd.Flags().String("body", "", "http tasks: request body")
+	cmd.Flags().String("body-file", "", "http tasks: path of a file used as request body")
+	cmd.Flags().String("content-type", "", "http tasks: request content type")
+
+	cmd.Flags().String("channel", "", "message tasks: channel the message is published to")
+	cmd.Flags().String("data", "", "message tasks: message data")
+}
+
+// taskMetaFromFlags returns the task's Meta built from the --meta flag or the
+// typed flags matching its type.
+func taskMetaFromFlags(cmd *cobra.Command, task model.Task) (string, bool) {
+	httpChanged := taskFlagsChanged(cmd, taskHTTPFlags)
+	msgChanged := taskFlagsChanged(cmd, taskMessageFlags)
+
+	if cmd.Flags().Changed("meta") {
+		if len(httpChanged) > 0 || len(msgChanged) > 0 {
+			printError("the --meta option cannot be combined with typed metadata flags")
+			return task.Meta, false
+		}
+
+		meta, _ := cmd.Flags().GetString("meta")
+		if len(meta) > 0 && !json.Valid([]byte(meta)) {
+			printError("invalid metadata: --meta must be valid JSON")
+			return task.Meta, false
+		}
+		return meta, true
+	}
+
+	switch task.Type {
+	case model.TaskTypeHTTP:
+		if len(msgChanged) > 0 {
+			printError("the --%s option only applies to message tasks", msgChanged[0])
+			return task.Meta, false
+		}
+		if len(httpChanged) == 0 {
+			return task.Meta, true
+		}
+		return taskHTTPMetaFromFlags(cmd, task.Meta)
+	case model.TaskTypeMessage:
+		if len(httpChanged) > 0 {
+			printError("the --%s option only applies to http tasks", httpChanged[0])
+			return task.Meta, false
+		}
+		if len(msgChanged) == 0 {
+			return task.Meta, true
+		}
+		return taskMessageMetaFromFlags(cmd, task.Meta)
+	}
+
+	if changed := append(httpChanged, msgChanged...); len(changed) > 0 {
+		printError("the --%s option does not apply to %s tasks", changed[0], task.Type)
+		return task.Meta, false
+	}
+
+	return task.Meta, true
+}
+
+func taskFlagsChanged(cmd *cobra.Command, names []string) []string {
+	var changed []string
+	for _, name := range names {
+		if cmd.Flags().Changed(name) {
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
+
+func taskHTTPMetaFromFlags(cmd *cobra.Command, current string) (string, bool) {
+	// typed flags update the existing metadata, only the provided fields change
+	meta, err := taskDecodeHTTPMeta(current)
+	if err != nil {
+		printError("%v, replace it with --meta", err)
+		return current, false
+	}
+
+	if cmd.Flags().Changed("method") {
+		meta.Method, _ = cmd.Flags().GetString("method")
+	}
+
+	if cmd.Flags().Changed("content-type") {
+		meta.ContentType, _ = cmd.Flags().GetString("content-type")
+	}
+
+	if cmd.Flags().Changed("body") && cmd.Flags().Changed("body-file") {
+		printError("the --body and --body-file options cannot be combined")
+		return current, false
+	} else if cmd.Flags().Changed("body") {
+		meta.Data, _ = cmd.Flags().GetString("body")
+	} else if cmd.Flags().Changed("body-file") {
+		path, _ := cmd.Flags().GetString("body-file")
+		b, err := os.ReadFile(path)
+		if err != nil {
+			printError("error reading body file: %v", err)
+			return current, false
+		}
+		meta.Data = string(b)
+	}
+
+	if err := taskValidateHTTPMeta(&meta); err != nil {
+		printError("invalid http task: %v", err)
+		return current, false
+	}
+
+	b, err := json.Marshal(meta)
+	if err != nil {
+		printError("unable to encode metadata: %v", err)
+		return current, false
+	}
+	return string(b), true
+}
+
+func taskMessageMetaFromFlags(cmd *cobra.Command, current string) (string, bool) {
+	meta, err := taskDecodeMessageMeta(current)
+	if err != nil {
+		printError("%v, replace it with --meta", err)
+		return current, false
+	}
+
+	if cmd.Flags().Changed("channel") {
+		meta.Channel, _ = cmd.Flags().GetString("channel")
+	}
+
+	if cmd.Flags().Changed("data") {
+		meta.Data, _ = cmd.Flags().GetString("data")
+	}
+
+	b, err := json.Marshal(meta)
+	if err != nil {
+		printError("unable to encode metadata: %v", err)
+		return current, false
+	}
+	return string(b), true
+}
+
+func parseTaskHeader(h string) (string, string, error) {
+	name, value, ok := strings.Cut(h, ":")
+	name = strings.TrimSpace(name)
+	if !ok || len(name) == 0 || strings.ContainsAny(name, " \t") {
+		return "", "", fmt.Errorf("%q must be in the form \"Name: value\"", h)
+	}
+	return name, strings.TrimSpace(value), nil
+}
+
+// taskValidateHTTPMeta normalizes the method and makes sure JSON bodies are
+// valid JSON.
+func taskValidateHTTPMeta(meta *taskHTTPMeta) error {
+	meta.Method = strings.ToUpper(strings.TrimSpace(meta.Method))
+	if len(meta.Method) == 0 {
+		meta.Method = "GET"
+	}
+
+	valid := false
+	for _, m := range taskHTTPMethods {
+		if m == meta.Method {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return fmt.Errorf("unsupported method %q, must be one of %s", meta.Method, strings.Join(taskHTTPMethods, ", "))
+	}
+
+	if strings.Contains(meta.ContentType, "json") && len(meta.Data) > 0 && !json.Valid([]byte(meta.Data)) {
+		return fmt.Errorf("the body is not valid JSON but the content type is %s", meta.ContentType)
+	}
+
+	return nil
+}
+
+func taskDecodeHTTPMeta(meta string) (taskHTTPMeta, error) {
+	m := taskHTTPMeta{Method: "GET"}
+	if len(meta) == 0 {
+		return m, nil
+	}
+
+	if err := json.Unmarshal([]byte(meta), &m); err != nil {
+		return m, fmt.Errorf("invalid http task metadata: %w", err)
+	}
+
+	if len(m.Method) == 0 {
+		m.Method = "GET"
+	}
+	return m, nil
+}
+
+func taskDecodeMessageMeta(meta string) (taskMessageMeta, error) {
+	var m taskMessageMeta
+	if len(meta) == 0 {
+		return m, nil
+	}
+
+	if err := json.Unmarshal([]byte(meta), &m); err != nil {
+		return m, fmt.Errorf("invalid message task metadata: %w", err)
+	}
+	return m, nil
+}
+
+// taskPrintMeta displays the task's metadata as readable fields, falling back
+// to the raw value when it cannot be decoded.
+func taskPrintMeta(task model.Task) {
+	if len(task.Meta) == 0 {
+		return
+	}
+
+	fmt.Printf("\n==== %s ====\n\n", clbold("META"))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	switch task.Type {
+	case model.TaskTypeHTTP:
+		meta, err := taskDecodeHTTPMeta(task.Meta)
+		if err != nil {
+			break
+		}
+
+		fmt.Fprintf(w, "method:\t%s\n", meta.Method)
+		if len(meta.ContentType) > 0 {
+			fmt.Fprintf(w, "content type:\t%s\n", meta.ContentType)
+		}
+
+		if len(meta.Data) > 0 {
+			fmt.Fprintf(w, "body:\t%s\n", meta.Data)
+		}
+		return
+	case model.TaskTypeMessage:
+		meta, err := taskDecodeMessageMeta(task.Meta)
+		if err != nil {
+			break
+		}
+
+		fmt.Fprintf(w, "channel:\t%s\n", meta.Channel)
+		fmt.Fprintf(w, "data:\t%s\n", meta.Data)
+		return
+	}
+
+	fmt.Fprintf(w, "%s\n", task.Meta)
+}
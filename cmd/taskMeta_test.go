@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/staticbackendhq/core/model"
+)
+
+func TestParseTaskHeader(t *testing.T) {
+	name, value, err := parseTaskHeader(" X-Api-Key :  secret:value ")
+	if err != nil {
+		t.Fatalf("parseTaskHeader returned error: %v", err)
+	}
+	if name != "X-Api-Key" || value != "secret:value" {
+		t.Fatalf("parseTaskHeader returned %q, %q", name, value)
+	}
+
+	for _, h := range []string{"no-colon", ": value", "Bad Name: value"} {
+		if _, _, err := parseTaskHeader(h); err == nil {
+			t.Fatalf("parseTaskHeader(%q) returned nil error", h)
+		}
+	}
+}
+
+func TestTaskValidateHTTPMeta(t *testing.T) {
+	meta := taskHTTPMeta{Method: "post", ContentType: "application/json", Data: `{"ok":true}`}
+	if err := taskValidateHTTPMeta(&meta); err != nil {
+		t.Fatalf("taskValidateHTTPMeta returned error: %v", err)
+	}
+	if meta.Method != "POST" {
+		t.Fatalf("taskValidateHTTPMeta did not normalize the method: %q", meta.Method)
+	}
+
+	invalid := []taskHTTPMeta{
+		{Method: "FETCH"},
+		{Method: "POST", ContentType: "application/json", Data: `{"ok":`},
+	}
+	for _, m := range invalid {
+		if err := taskValidateHTTPMeta(&m); err == nil {
+			t.Fatalf("taskValidateHTTPMeta(%+v) returned nil error", m)
+		}
+	}
+}
+
+func TestTaskDecodeHTTPMeta(t *testing.T) {
+	meta, err := taskDecodeHTTPMeta(`{"ct":"application/json","data":"{\"ok\":true}"}`)
+	if err != nil {
+		t.Fatalf("taskDecodeHTTPMeta returned error: %v", err)
+	}
+
+	if meta.Method != "GET" || meta.ContentType != "application/json" || meta.Data != `{"ok":true}` {
+		t.Fatalf("taskDecodeHTTPMeta returned %+v", meta)
+	}
+
+	if _, err := taskDecodeHTTPMeta("not json"); err == nil {
+		t.Fatal("taskDecodeHTTPMeta returned nil error for invalid JSON")
+	}
+}
+
+func TestTaskMetaFromFlagsKeepsUndecodableMeta(t *testing.T) {
+	cmd := &cobra.Command{}
+	addTaskMetaFlags(cmd)
+	if err := cmd.Flags().Set("method", "POST"); err != nil {
+		t.Fatal(err)
+	}
+
+	task := model.Task{Type: model.TaskTypeHTTP, Meta: "not json"}
+	if meta, ok := taskMetaFromFlags(cmd, task); ok || meta != task.Meta {
+		t.Fatalf("taskMetaFromFlags = %q, %v, want the metadata kept and an error", meta, ok)
+	}
+}
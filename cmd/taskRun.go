@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/staticbackendhq/backend-go"
+	"github.com/staticbackendhq/core/model"
+)
+
+var taskNextCmd = &cobra.Command{
+	Use:   "next id",
+	Short: "Preview the upcoming run times of a scheduled task",
+	Long: fmt.Sprintf(`
+%s
+
+Displays the next times the task will run, in UTC and in your local time.
+
+backend task next task-id --count 5
+
+Use %s to preview an interval before creating a task:
+
+backend task next --interval "0 2 * * 1-5"
+
+Runs of "@every <duration>" intervals are counted from when the server
+scheduled the task, the times displayed are counted from now.
+	`,
+		clbold("Preview scheduled task runs"),
+		clbold("--interval"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		interval, _ := cmd.Flags().GetString("interval")
+
+		if len(interval) == 0 {
+			if len(args) != 1 {
+				printError("argument mismatch: one task id or --interval should be specified")
+				return
+			}
+
+			if setBackend() == false {
+				return
+			}
+
+			tok, ok := getRootToken()
+			if !ok {
+				return
+			}
+
+			task, err := taskInfo(tok, args[0])
+			if err != nil {
+				printError("error retrieving task: %v", err)
+				return
+			}
+
+			interval = task.Interval
+		}
+
+		sched, err := parseCron(interval)
+		if err != nil {
+			printError("invalid interval %q: %v", interval, err)
+			return
+		}
+
+		count, _ := cmd.Flags().GetInt("count")
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
+		fmt.Fprintf(w, "UTC\tLOCAL\n")
+		for _, t := range taskNextRuns(sched, time.Now(), count) {
+			fmt.Fprintf(w, "%s\t%s\n",
+				t.Format("2006/01/02 15:04 MST"),
+				t.Local().Format("2006/01/02 15:04 MST"),
+			)
+		}
+		w.Flush()
+	},
+}
+
+var taskRunCmd = &cobra.Command{
+	Use:   "run id",
+	Short: "Execute a scheduled task now",
+	Long: fmt.Sprintf(`
+%s
+
+Immediately executes the task's target from your machine without waiting
+for its schedule:
+
+%s: runs the function with your root token
+%s: publishes the message to the channel from the task metadata
+%s: calls the URL with the method, headers, and body from the task metadata
+
+Use %s to add request headers to http tasks, they are not stored in the task
+since the server's scheduler does not send custom headers.
+	`,
+		clbold("Run a scheduled task now"),
+		clbold("function"),
+		clbold("message"),
+		clbold("http"),
+		clbold("--header"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		if setBackend() == false {
+			return
+		}
+
+		tok, ok := getRootToken()
+		if !ok {
+			return
+		}
+
+		if len(args) != 1 {
+			printError("argument mismatch: one task id should be specified")
+			return
+		}
+
+		task, err := taskInfo(tok, args[0])
+		if err != nil {
+			printError("error retrieving task: %v", err)
+			return
+		}
+
+		headers := map[string]string{}
+		raw, _ := cmd.Flags().GetStringArray("header")
+		for _, h := range raw {
+			name, value, err := parseTaskHeader(h)
+			if err != nil {
+				printError("invalid --header: %v", err)
+				return
+			}
+			headers[name] = value
+		}
+
+		if len(headers) > 0 && !strings.EqualFold(task.Type, model.TaskTypeHTTP) {
+			printError("the --header option only applies to http tasks")
+			return
+		}
+
+		if err := taskExecute(tok, task, headers); err != nil {
+			printError("error running task %s: %v", task.Name, err)
+			return
+		}
+
+		printSuccess("Task %s executed", clbold(task.Name))
+	},
+}
+
+func init() {
+	taskCmd.AddCommand(taskNextCmd)
+	taskCmd.AddCommand(taskRunCmd)
+
+	taskNextCmd.Flags().Int("count", 5, "number of upcoming runs to display")
+	taskNextCmd.Flags().String("interval", "", "preview a cron interval instead of an existing task")
+
+	taskRunCmd.Flags().StringArray("header", nil, "http tasks: request header as \"Name: value\", may be repeated")
+}
+
+func taskNextRuns(sched cronSchedule, from time.Time, count int) []time.Time {
+	var runs []time.Time
+	for i := 0; i < count; i++ {
+		from = sched.next(from)
+		if from.IsZero() {
+			break
+		}
+		runs = append(runs, from)
+	}
+	return runs
+}
+
+func taskExecute(tok string, task model.Task, headers map[string]string) error {
+	switch strings.ToLower(task.Type) {
+	case model.TaskTypeFunction:
+		return backend.Post(tok, functionRunPath(task.Value, true), map[string]any{}, nil)
+	case model.TaskTypeMessage:
+		meta, err := taskDecodeMessageMeta(task.Meta)
+		if err != nil {
+			return err
+		}
+
+		if len(meta.Channel) == 0 {
+			return fmt.Errorf("the task metadata has no channel to publish to")
+		}
+
+		var data any = meta.Data
+		if json.Valid([]byte(meta.Data)) {
+			_ = json.Unmarshal([]byte(meta.Data), &data)
+		}
+
+		return backend.Publish(tok, meta.Channel, task.Value, data)
+	case model.TaskTypeHTTP:
+		meta, err := taskDecodeHTTPMeta(task.Meta)
+		if err != nil {
+			return err
+		}
+
+		return taskExecuteHTTP(task.Value, meta, headers)
+	}
+
+	return fmt.Errorf("unsupported task type %q", task.Type)
+}
+
+func taskExecuteHTTP(target string, meta taskHTTPMeta, headers map[string]string) error {
+	var body io.Reader
+	if len(meta.Data) > 0 {
+		body = strings.NewReader(meta.Data)
+	}
+
+	req, err := http.NewRequest(strings.ToUpper(meta.Method), target, body)
+	if err != nil {
+		return err
+	}
+
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	if len(meta.ContentType) > 0 {
+		req.Header.Set("Content-Type", meta.ContentType)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	fmt.Printf("%s %s: %s\n\n", req.Method, target, clbold(resp.Status))
+	if len(b) > 0 {
+		fmt.Printf("%s\n\n", b)
+	}
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("the endpoint responded with %s", resp.Status)
+	}
+	return nil
+}
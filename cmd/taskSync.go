@@ -0,0 +1,410 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/staticbackendhq/core/model"
+	"gopkg.in/yaml.v2"
+)
+
+var taskExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export scheduled tasks as YAML",
+	Long: fmt.Sprintf(`
+%s
+
+Writes all scheduled tasks in a YAML file that "backend task apply" accepts,
+for instance to copy tasks from staging to production:
+
+backend task export > tasks.yml
+backend task apply tasks.yml --config .backend.prod.yml
+	`,
+		clbold("Export scheduled tasks"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		if setBackend() == false {
+			return
+		}
+
+		tok, ok := getRootToken()
+		if !ok {
+			return
+		}
+
+		tasks, err := taskList(tok)
+		if err != nil {
+			printError("error listing tasks: %v", err)
+			return
+		}
+
+		file := taskFile{Tasks: make([]taskSpec, 0, len(tasks))}
+		for _, task := range tasks {
+			file.Tasks = append(file.Tasks, taskSpecFromTask(task))
+		}
+
+		sort.Slice(file.Tasks, func(i, j int) bool {
+			return file.Tasks[i].Name < file.Tasks[j].Name
+		})
+
+		b, err := yaml.Marshal(file)
+		if err != nil {
+			printError("unable to encode tasks: %v", err)
+			return
+		}
+
+		fmt.Print(string(b))
+	},
+}
+
+var taskApplyCmd = &cobra.Command{
+	Use:   "apply file",
+	Short: "Create, update, and delete scheduled tasks to match a YAML file",
+	Long: fmt.Sprintf(`
+%s
+
+Tasks are matched by name. Tasks in the file that don't exist are created and
+existing tasks whose type, value, interval, or meta differ are updated.
+
+Tasks that are not in the file are only deleted with %s.
+
+Use %s to display the changes without applying them.
+	`,
+		clbold("Apply scheduled tasks"),
+		clbold("--prune"),
+		clbold("--dry-run"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		if setBackend() == false {
+			return
+		}
+
+		tok, ok := getRootToken()
+		if !ok {
+			return
+		}
+
+		if len(args) != 1 {
+			printError("argument mismatch: one file should be specified")
+			return
+		}
+
+		desired, err := taskReadFile(args[0])
+		if err != nil {
+			printError("invalid task file: %v", err)
+			return
+		}
+
+		current, err := taskList(tok)
+		if err != nil {
+			printError("error listing tasks: %v", err)
+			return
+		}
+
+		prune, _ := cmd.Flags().GetBool("prune")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		changes := taskPlan(desired, current, prune)
+		if len(changes) == 0 {
+			printSuccess("Tasks are up to date")
+			return
+		}
+
+		for _, c := range changes {
+			taskPrintChange(c)
+		}
+
+		if dryRun {
+			fmt.Printf("\n%s change(s), nothing applied (--dry-run)\n", clbold(len(changes)))
+			return
+		}
+
+		fmt.Println()
+		for _, c := range changes {
+			if err := taskApplyChange(tok, c); err != nil {
+				printError("error applying %s of %s: %v", c.action, c.name, err)
+				return
+			}
+		}
+
+		printSuccess("%d change(s) applied", len(changes))
+	},
+}
+
+func init() {
+	taskCmd.AddCommand(taskExportCmd)
+	taskCmd.AddCommand(taskApplyCmd)
+
+	taskApplyCmd.Flags().Bool("prune", false, "delete tasks that are not in the file")
+	taskApplyCmd.Flags().Bool("dry-run", false, "display the changes without applying them")
+}
+
+// taskFile is the YAML representation of scheduled tasks.
+type taskFile struct {
+	Tasks []taskSpec `yaml:"tasks"`
+}
+
+type taskSpec struct {
+	Name     string `yaml:"name"`
+	Type     string `yaml:"type"`
+	Value    string `yaml:"value"`
+	Interval string `yaml:"interval"`
+	// Meta is kept structured in YAML and stored as JSON on the task.
+	Meta any `yaml:"meta,omitempty"`
+}
+
+const (
+	taskActionCreate = "create"
+	taskActionUpdate = "update"
+	taskActionDelete = "delete"
+)
+
+type taskChange struct {
+	action string
+	name   string
+	id     string
+	task   model.Task
+	// diff holds field name, old value, new value
+	diff [][3]string
+}
+
+func taskSpecFromTask(task model.Task) taskSpec {
+	spec := taskSpec{
+		Name:     task.Name,
+		Type:     task.Type,
+		Value:    task.Value,
+		Interval: task.Interval,
+	}
+
+	if len(task.Meta) > 0 {
+		var meta any
+		if err := json.Unmarshal([]byte(task.Meta), &meta); err == nil {
+			spec.Meta = meta
+		} else {
+			spec.Meta = task.Meta
+		}
+	}
+
+	return spec
+}
+
+func (spec taskSpec) toTask() (model.Task, error) {
+	task := model.Task{
+		Name:     spec.Name,
+		Type:     spec.Type,
+		Value:    spec.Value,
+		Interval: spec.Interval,
+	}
+
+	switch meta := spec.Meta.(type) {
+	case nil:
+	case string:
+		task.Meta = meta
+	default:
+		b, err := json.Marshal(yamlToJSONValue(meta))
+		if err != nil {
+			return task, fmt.Errorf("task %s: invalid meta: %w", spec.Name, err)
+		}
+		task.Meta = string(b)
+	}
+
+	return task, nil
+}
+
+// yamlToJSONValue converts the map[interface{}]interface{} produced by the
+// YAML decoder into values encoding/json accepts.
+func yamlToJSONValue(v any) any {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]any, len(v))
+		for k, val := range v {
+			m[fmt.Sprintf("%v", k)] = yamlToJSONValue(val)
+		}
+		return m
+	case []interface{}:
+		for i := range v {
+			v[i] = yamlToJSONValue(v[i])
+		}
+		return v
+	}
+	return v
+}
+
+func taskReadFile(path string) ([]model.Task, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file taskFile
+	if err := yaml.UnmarshalStrict(b, &file); err != nil {
+		return nil, err
+	}
+
+	return taskParseSpecs(file.Tasks)
+}
+
+func taskParseSpecs(specs []taskSpec) ([]model.Task, error) {
+	seen := map[string]bool{}
+	tasks := make([]model.Task, 0, len(specs))
+	for i, spec := range specs {
+		if len(spec.Name) == 0 {
+			return nil, fmt.Errorf("task #%d has no name", i+1)
+		}
+		if seen[spec.Name] {
+			return nil, fmt.Errorf("task %s is defined more than once", spec.Name)
+		}
+		seen[spec.Name] = true
+
+		if !taskValidType(spec.Type) {
+			return nil, fmt.Errorf("task %s: invalid type %q, must be function, message, or http", spec.Name, spec.Type)
+		}
+		// the server stores the lowercase type, compared by taskDiff
+		spec.Type = strings.ToLower(spec.Type)
+		if len(spec.Value) == 0 {
+			return nil, fmt.Errorf("task %s: missing value", spec.Name)
+		}
+		if _, err := parseCron(spec.Interval); err != nil {
+			return nil, fmt.Errorf("task %s: invalid interval %q: %w", spec.Name, spec.Interval, err)
+		}
+
+		task, err := spec.toTask()
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// taskPlan returns the changes required for current to match desired, in
+// create, update, delete order.
+func taskPlan(desired, current []model.Task, prune bool) []taskChange {
+	byName := map[string]model.Task{}
+	for _, task := range current {
+		byName[task.Name] = task
+	}
+
+	var changes []taskChange
+	wanted := map[string]bool{}
+	for _, want := range desired {
+		wanted[want.Name] = true
+
+		have, ok := byName[want.Name]
+		if !ok {
+			changes = append(changes, taskChange{action: taskActionCreate, name: want.Name, task: want})
+			continue
+		}
+
+		diff := taskDiff(have, want)
+		if len(diff) == 0 {
+			continue
+		}
+
+		want.ID = have.ID
+		changes = append(changes, taskChange{
+			action: taskActionUpdate,
+			name:   want.Name,
+			id:     have.ID,
+			task:   want,
+			diff:   diff,
+		})
+	}
+
+	if prune {
+		for _, have := range current {
+			if !wanted[have.Name] {
+				changes = append(changes, taskChange{action: taskActionDelete, name: have.Name, id: have.ID, task: have})
+			}
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return taskActionOrder(changes[i].action) < taskActionOrder(changes[j].action)
+	})
+
+	return changes
+}
+
+func taskActionOrder(action string) int {
+	switch action {
+	case taskActionCreate:
+		return 0
+	case taskActionUpdate:
+		return 1
+	}
+	return 2
+}
+
+func taskDiff(have, want model.Task) [][3]string {
+	var diff [][3]string
+	if have.Type != want.Type {
+		diff = append(diff, [3]string{"type", have.Type, want.Type})
+	}
+	if have.Value != want.Value {
+		diff = append(diff, [3]string{"value", have.Value, want.Value})
+	}
+	if have.Interval != want.Interval {
+		diff = append(diff, [3]string{"interval", have.Interval, want.Interval})
+	}
+	if !taskMetaEqual(have.Meta, want.Meta) {
+		diff = append(diff, [3]string{"meta", have.Meta, want.Meta})
+	}
+	return diff
+}
+
+// taskMetaEqual compares JSON metadata regardless of key order and spacing.
+func taskMetaEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	var va, vb any
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return false
+	}
+
+	ba, _ := json.Marshal(va)
+	bb, _ := json.Marshal(vb)
+	return bytes.Equal(ba, bb)
+}
+
+func taskPrintChange(c taskChange) {
+	switch c.action {
+	case taskActionCreate:
+		fmt.Printf("+ create %s (%s %s, %q)\n", clbold(c.name), c.task.Type, c.task.Value, c.task.Interval)
+	case taskActionUpdate:
+		fmt.Printf("~ update %s\n", clbold(c.name))
+		for _, d := range c.diff {
+			fmt.Printf("\t%s: %q => %q\n", d[0], d[1], d[2])
+		}
+	case taskActionDelete:
+		fmt.Printf("- delete %s\n", clbold(c.name))
+	}
+}
+
+func taskApplyChange(tok string, c taskChange) error {
+	switch c.action {
+	case taskActionCreate:
+		if _, err := taskAdd(tok, c.task); err != nil {
+			return err
+		}
+	case taskActionUpdate:
+		if _, err := taskUpdate(tok, c.id, c.task); err != nil {
+			return err
+		}
+	case taskActionDelete:
+		if err := taskDelete(tok, c.id); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("%s %s: done\n", c.action, c.name)
+	return nil
+}
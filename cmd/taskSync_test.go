@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/staticbackendhq/core/model"
+	"gopkg.in/yaml.v2"
+)
+
+func TestTaskParseSpecsFromYAML(t *testing.T) {
+	src := `
+tasks:
+  - name: ping
+    type: HTTP
+    value: https://example.com/hook
+    interval: "*/15 * * * *"
+    meta:
+      method: POST
+      ct: application/json
+      data: '{"ok":true}'
+  - name: cleanup
+    type: function
+    value: cleanup
+    interval: "0 2 * * *"
+`
+
+	var file taskFile
+	if err := yaml.UnmarshalStrict([]byte(src), &file); err != nil {
+		t.Fatalf("unable to decode YAML: %v", err)
+	}
+
+	tasks, err := taskParseSpecs(file.Tasks)
+	if err != nil {
+		t.Fatalf("taskParseSpecs returned error: %v", err)
+	}
+
+	if len(tasks) != 2 {
+		t.Fatalf("taskParseSpecs returned %d tasks, want 2", len(tasks))
+	}
+
+	if !taskMetaEqual(tasks[0].Meta, `{"ct":"application/json","data":"{\"ok\":true}","method":"POST"}`) {
+		t.Fatalf("unexpected meta %q", tasks[0].Meta)
+	}
+
+	if tasks[0].Type != "http" {
+		t.Fatalf("type = %q, want it lowercased", tasks[0].Type)
+	}
+
+	if tasks[1].Meta != "" {
+		t.Fatalf("unexpected meta %q for a task without meta", tasks[1].Meta)
+	}
+}
+
+func TestTaskParseSpecsRejectsInvalidTasks(t *testing.T) {
+	tests := [][]taskSpec{
+		{{Name: "a", Type: "function", Value: "fn", Interval: "0 2 * * *"}, {Name: "a", Type: "function", Value: "fn", Interval: "0 2 * * *"}},
+		{{Name: "a", Type: "cron", Value: "fn", Interval: "0 2 * * *"}},
+		{{Name: "a", Type: "function", Value: "fn", Interval: "every day"}},
+		{{Type: "function", Value: "fn", Interval: "0 2 * * *"}},
+	}
+
+	for _, specs := range tests {
+		if _, err := taskParseSpecs(specs); err == nil {
+			t.Fatalf("taskParseSpecs(%+v) returned nil error", specs)
+		}
+	}
+}
+
+func TestTaskPlan(t *testing.T) {
+	current := []model.Task{
+		{ID: "1", Name: "same", Type: "function", Value: "fn", Interval: "0 2 * * *", Meta: `{"a": 1, "b": 2}`},
+		{ID: "2", Name: "changed", Type: "function", Value: "fn", Interval: "0 2 * * *"},
+		{ID: "3", Name: "extra", Type: "function", Value: "fn", Interval: "0 2 * * *"},
+	}
+
+	desired := []model.Task{
+		{Name: "new", Type: "function", Value: "fn", Interval: "0 3 * * *"},
+		{Name: "same", Type: "function", Value: "fn", Interval: "0 2 * * *", Meta: `{"b":2,"a":1}`},
+		{Name: "changed", Type: "function", Value: "fn", Interval: "0 4 * * *"},
+	}
+
+	changes := taskPlan(desired, current, false)
+	if len(changes) != 2 {
+		t.Fatalf("taskPlan returned %d changes, want 2: %+v", len(changes), changes)
+	}
+
+	if changes[0].action != taskActionCreate || changes[0].name != "new" {
+		t.Fatalf("unexpected first change %+v", changes[0])
+	}
+
+	if c := changes[1]; c.action != taskActionUpdate || c.id != "2" || len(c.diff) != 1 || c.diff[0][0] != "interval" {
+		t.Fatalf("unexpected second change %+v", c)
+	}
+
+	changes = taskPlan(desired, current, true)
+	if len(changes) != 3 || changes[2].action != taskActionDelete || changes[2].id != "3" {
+		t.Fatalf("taskPlan with prune returned %+v", changes)
+	}
+}
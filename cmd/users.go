@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/staticbackendhq/backend-go"
 )
 
 // usersCmd represents the users command
@@ -13,7 +14,8 @@ var usersCmd = &cobra.Command{
 	Long: fmt.Sprintf(`
 %s
 
-You may list, add, and delete users for your application.
+You may list, add, and delete users for your application, change their role,
+reset their password, and run commands on their behalf.
 	`,
 		clbold("Manage your application users"),
 	),
@@ -22,3 +24,32 @@ You may list, add, and delete users for your application.
 func init() {
 	rootCmd.AddCommand(usersCmd)
 }
+
+// usersAccountID returns the --account-id flag value, the --account global
+// flag value, or the account of the authenticated user.
+func usersAccountID(cmd *cobra.Command) (string, bool) {
+	if id, _ := cmd.Flags().GetString("account-id"); len(id) > 0 {
+		return id, true
+	}
+
+	if len(accountFlag) > 0 {
+		return accountFlag, true
+	}
+
+	authToken, ok := getAuthToken()
+	if !ok {
+		return "", false
+	}
+
+	me, err := backend.Me(authToken)
+	if err != nil {
+		printError("unable to retrieve your account: %v", err)
+		return "", false
+	}
+
+	return me.AccountID, true
+}
+
+func addUsersAccountIDFlag(cmd *cobra.Command) {
+	cmd.Flags().String("account-id", "", "account of the user, defaults to your account")
+}
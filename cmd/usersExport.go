@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/staticbackendhq/backend-go"
+)
+
+// usersExportCmd writes all users as CSV or JSON
+var usersExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export all users as CSV or JSON.",
+	Long: fmt.Sprintf(`
+%s
+
+Writes every user of your application with their role and creation date.
+
+$> backend users export --output csv > users.csv
+$> backend users export --output json --file users.json
+	`,
+		clbold("Export application users"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !setBackend() {
+			return
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		if output != "csv" && output != "json" {
+			printError("invalid --output %q: must be csv or json", output)
+			return
+		}
+
+		authToken, ok := getAuthToken()
+		if !ok {
+			return
+		}
+
+		users, err := backend.Users(authToken)
+		if err != nil {
+			printError("An error occurred: %v", err)
+			return
+		}
+
+		var w io.Writer = os.Stdout
+		if dest, _ := cmd.Flags().GetString("file"); len(dest) > 0 {
+			f, err := os.Create(dest)
+			if err != nil {
+				printError("could not create %s: %v", dest, err)
+				return
+			}
+			defer f.Close()
+			w = f
+		}
+
+		if output == "json" {
+			err = usersExportJSON(w, users)
+		} else {
+			err = usersExportCSV(w, users)
+		}
+		if err != nil {
+			printError("An error occurred: %v", err)
+		}
+	},
+}
+
+func init() {
+	usersCmd.AddCommand(usersExportCmd)
+
+	usersExportCmd.Flags().StringP("output", "o", "csv", "export format: csv or json")
+	usersExportCmd.Flags().String("file", "", "write to this file instead of stdout")
+}
+
+type usersExportEntry struct {
+	ID        string    `json:"id"`
+	AccountID string    `json:"accountId"`
+	Email     string    `json:"email"`
+	Role      int       `json:"role"`
+	Created   time.Time `json:"created"`
+}
+
+func usersExportJSON(w io.Writer, users []backend.User) error {
+	entries := make([]usersExportEntry, 0, len(users))
+	for _, u := range users {
+		entries = append(entries, usersExportEntry{
+			ID:        u.ID,
+			AccountID: u.AccountID,
+			Email:     u.Email,
+			Role:      u.Role,
+			Created:   u.Created,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+func usersExportCSV(w io.Writer, users []backend.User) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "accountId", "email", "role", "created"})
+	for _, u := range users {
+		cw.Write([]string{
+			u.ID,
+			u.AccountID,
+			u.Email,
+			strconv.Itoa(u.Role),
+			u.Created.UTC().Format(time.RFC3339),
+		})
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/staticbackendhq/backend-go"
+)
+
+// usersGetCmd displays a user
+var usersGetCmd = &cobra.Command{
+	Use:   "get <userID>",
+	Short: "Display a user by its ID.",
+	Long: fmt.Sprintf(`
+%s
+
+Retrieves the user with the given ID. You'll need a rootToken in your config
+file.
+	`,
+		clbold("Get an application user"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !setBackend() {
+			return
+		}
+
+		if len(args) == 0 {
+			printError("Argument missing: userID — please supply a user ID.")
+			return
+		}
+
+		tok, ok := getRootToken()
+		if !ok {
+			return
+		}
+
+		accountID, ok := usersAccountID(cmd)
+		if !ok {
+			return
+		}
+
+		user, err := backend.SudoGetUserByID(tok, accountID, args[0])
+		if err != nil {
+			printError("An error occurred: %v", err)
+			return
+		}
+
+		fmt.Printf("ID:\t\t%s\n", user.ID)
+		fmt.Printf("Account ID:\t%s\n", user.AccountID)
+		fmt.Printf("Email:\t\t%s\n", user.Email)
+		fmt.Printf("Role:\t\t%d\n", user.Role)
+		fmt.Printf("Created:\t%s\n", user.Created.Format("2006/01/02 15:04"))
+	},
+}
+
+func init() {
+	usersCmd.AddCommand(usersGetCmd)
+	addUsersAccountIDFlag(usersGetCmd)
+}
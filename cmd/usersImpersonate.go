@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+	"github.com/staticbackendhq/backend-go"
+)
+
+// usersImpersonateCmd gets an auth token for a user
+var usersImpersonateCmd = &cobra.Command{
+	Use:   "impersonate <userID> [-- command]",
+	Short: "Get an auth token for a user or run a command as that user.",
+	Long: fmt.Sprintf(`
+%s
+
+Without a command, prints an auth token for the user.
+
+When a command follows %s, it runs with the user's auth token instead of
+yours, which is useful to reproduce user-specific issues:
+
+$> backend users impersonate user-id -- function run my_fn --data '{"id": 1}'
+$> backend users impersonate user-id -- users list
+	`,
+		clbold("Impersonate an application user"),
+		clbold("--"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !setBackend() {
+			return
+		}
+
+		sub := []string{}
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+			args, sub = args[:dash], args[dash:]
+		}
+
+		if len(args) == 0 {
+			printError("Argument missing: userID — please supply a user ID.")
+			return
+		}
+
+		tok, ok := getRootToken()
+		if !ok {
+			return
+		}
+
+		accountID, ok := usersAccountID(cmd)
+		if !ok {
+			return
+		}
+
+		userTok, err := backend.SudoGetAuthTokenByUserID(tok, accountID, args[0])
+		if err != nil {
+			printError("An error occurred: %v", err)
+			return
+		}
+
+		if len(sub) == 0 {
+			fmt.Println(userTok)
+			return
+		}
+
+		if err := runAsUser(userTok, sub); err != nil {
+			printError("%v", err)
+		}
+	},
+}
+
+func init() {
+	usersCmd.AddCommand(usersImpersonateCmd)
+	addUsersAccountIDFlag(usersImpersonateCmd)
+}
+
+// runAsUser executes the CLI with the provided arguments, overriding the
+// authToken config entry via the environment.
+func runAsUser(authToken string, args []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	if len(cfgFile) > 0 {
+		args = append(args, "--config", cfgFile)
+	}
+
+	c := exec.Command(exe, args...)
+	c.Env = append(os.Environ(), "AUTHTOKEN="+authToken)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c.Run()
+}
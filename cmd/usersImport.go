@@ -0,0 +1,368 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/staticbackendhq/backend-go"
+)
+
+// usersImportCmd creates users from a CSV file
+var usersImportCmd = &cobra.Command{
+	Use:   "import <file.csv>",
+	Short: "Import users from a CSV file.",
+	Long: fmt.Sprintf(`
+%s
+
+The CSV file has the columns: email, password, role. A header row is optional,
+when present the columns are matched by name and the other ones are ignored,
+so a file from "backend users export" can be imported back.
+
+When the password is empty a random one is generated, and when the role is
+empty the default role is kept. Emails already registered are skipped, their
+role is still applied, so an import can safely be run again after a failure.
+
+$> backend users import users.csv --concurrency 8 --rate 20 --report report.csv
+
+The report lists every row with its status and error, including generated
+passwords, keep it somewhere safe. It is required when some rows have no
+password, otherwise nobody would know the generated ones.
+	`,
+		clbold("Import application users"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !setBackend() {
+			return
+		}
+
+		if len(args) == 0 {
+			printError("Argument missing: file — please supply a CSV file.")
+			return
+		}
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			printError("error reading CSV file: %v", err)
+			return
+		}
+		defer f.Close()
+
+		rows, err := usersImportParse(f)
+		if err != nil {
+			printError("invalid CSV file: %v", err)
+			return
+		}
+
+		report, _ := cmd.Flags().GetString("report")
+		if len(report) == 0 && usersImportGeneratesPasswords(rows) {
+			printError("some rows have no password: use --report to record the generated passwords")
+			return
+		}
+
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		if concurrency < 1 {
+			concurrency = 1
+		}
+
+		rate, _ := cmd.Flags().GetFloat64("rate")
+		if rate < 0 || rate > usersImportMaxRate {
+			printError("invalid --rate: must be between 0 and %d", usersImportMaxRate)
+			return
+		}
+
+		authToken, ok := getAuthToken()
+		if !ok {
+			return
+		}
+
+		var rootToken, accountID string
+		if usersImportHasRoles(rows) {
+			rootToken, ok = getRootToken()
+			if !ok {
+				return
+			}
+
+			accountID, ok = usersAccountID(cmd)
+			if !ok {
+				return
+			}
+		}
+
+		results := usersImportRun(rows, concurrency, rate, func(row *usersImportRow) error {
+			return usersImportOne(authToken, rootToken, accountID, row)
+		})
+
+		var created, skipped, failed int
+		for _, row := range results {
+			switch row.Status {
+			case usersImportCreated:
+				created++
+			case usersImportSkipped:
+				skipped++
+			default:
+				failed++
+				printError("line %d (%s): %s", row.Line, row.Email, row.Err)
+			}
+		}
+
+		if len(report) > 0 {
+			if err := usersImportWriteReport(report, results); err != nil {
+				printError("could not write the report: %v", err)
+			}
+		}
+
+		fmt.Printf("\n%s created, %s skipped, %s failed\n",
+			clbold(created), clbold(skipped), clbold(failed))
+	},
+}
+
+func init() {
+	usersCmd.AddCommand(usersImportCmd)
+
+	usersImportCmd.Flags().Int("concurrency", 4, "number of users created in parallel")
+	usersImportCmd.Flags().Float64("rate", 10, "maximum users created per second, 0 for no limit")
+	usersImportCmd.Flags().String("report", "", "path of a CSV report of every imported row")
+	addUsersAccountIDFlag(usersImportCmd)
+}
+
+// usersImportMaxRate is the highest --rate, past it the ticker interval
+// would round down to nothing.
+const usersImportMaxRate = 1000
+
+const (
+	usersImportCreated = "created"
+	usersImportSkipped = "skipped"
+	usersImportFailed  = "failed"
+)
+
+type usersImportRow struct {
+	Line      int
+	Email     string
+	Password  string
+	Generated bool
+	Role      int
+	HasRole   bool
+	Status    string
+	Err       string
+}
+
+// usersImportColumns are the default columns, used when the file has no
+// header row.
+var usersImportColumns = map[string]int{"email": 0, "password": 1, "role": 2}
+
+// usersImportHeader returns the column of each known field when rec is a
+// header row, naming an email column.
+func usersImportHeader(rec []string) (map[string]int, bool) {
+	cols := map[string]int{}
+	for i, name := range rec {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := usersImportColumns[name]; ok {
+			if _, dup := cols[name]; !dup {
+				cols[name] = i
+			}
+		}
+	}
+
+	_, ok := cols["email"]
+	return cols, ok
+}
+
+func usersImportParse(r io.Reader) ([]*usersImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	// cell returns the trimmed value of a field, empty when the row or the
+	// file doesn't have it
+	cols := usersImportColumns
+	cell := func(rec []string, field string) string {
+		i, ok := cols[field]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+
+	var rows []*usersImportRow
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		// the header maps the columns by name, like the ones of "users export"
+		if line == 1 {
+			if header, ok := usersImportHeader(rec); ok {
+				cols = header
+				continue
+			}
+		}
+
+		email := cell(rec, "email")
+		if len(email) == 0 {
+			continue
+		}
+
+		row := &usersImportRow{Line: line, Email: email, Password: cell(rec, "password")}
+
+		if role := cell(rec, "role"); len(role) > 0 {
+			n, err := strconv.Atoi(role)
+			if err != nil || n < 0 || n > 100 {
+				return nil, fmt.Errorf("line %d: invalid role %q", line, role)
+			}
+			row.Role = n
+			row.HasRole = true
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// usersImportGeneratesPasswords reports whether some rows have no password
+// and would get a random one.
+func usersImportGeneratesPasswords(rows []*usersImportRow) bool {
+	for _, row := range rows {
+		if len(row.Password) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func usersImportHasRoles(rows []*usersImportRow) bool {
+	for _, row := range rows {
+		if row.HasRole {
+			return true
+		}
+	}
+	return false
+}
+
+// usersImportRun calls fn for every row using concurrency workers while
+// limiting the calls to rate per second.
+func usersImportRun(rows []*usersImportRow, concurrency int, rate float64, fn func(*usersImportRow) error) []*usersImportRow {
+	var throttle <-chan time.Time
+	if rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	jobs := make(chan *usersImportRow)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range jobs {
+				if err := fn(row); err != nil {
+					row.Status = usersImportFailed
+					row.Err = err.Error()
+				}
+			}
+		}()
+	}
+
+	for _, row := range rows {
+		if throttle != nil {
+			<-throttle
+		}
+		jobs <- row
+	}
+	close(jobs)
+
+	wg.Wait()
+	return rows
+}
+
+func usersImportOne(authToken, rootToken, accountID string, row *usersImportRow) error {
+	exists, err := backend.EmailExists(row.Email)
+	if err != nil {
+		return err
+	} else if exists {
+		row.Status = usersImportSkipped
+
+		// a previous import may have created the user without its role
+		if row.HasRole {
+			if err := backend.SetRole(rootToken, accountID, row.Email, row.Role); err != nil {
+				return fmt.Errorf("user exists but setting role failed: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if len(row.Password) == 0 {
+		pw, err := randomPassword(16)
+		if err != nil {
+			return err
+		}
+		row.Password = pw
+		row.Generated = true
+	}
+
+	if _, err := backend.AddUser(authToken, row.Email, row.Password); err != nil {
+		return err
+	}
+
+	row.Status = usersImportCreated
+
+	if row.HasRole {
+		if err := backend.SetRole(rootToken, accountID, row.Email, row.Role); err != nil {
+			return fmt.Errorf("user created but setting role failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func usersImportWriteReport(path string, rows []*usersImportRow) error {
+	// the report holds the generated passwords
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"line", "email", "status", "generated_password", "error"})
+	for _, row := range rows {
+		var pw string
+		if row.Generated {
+			pw = row.Password
+		}
+
+		w.Write([]string{strconv.Itoa(row.Line), row.Email, row.Status, pw, row.Err})
+	}
+	w.Flush()
+
+	return w.Error()
+}
+
+func randomPassword(n int) (string, error) {
+	const chars = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	b := make([]byte, n)
+	for i := range b {
+		idx, err := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
+		if err != nil {
+			return "", err
+		}
+		b[i] = chars[idx.Int64()]
+	}
+
+	return string(b), nil
+}
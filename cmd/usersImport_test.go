@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/staticbackendhq/backend-go"
+)
+
+func TestUsersImportParse(t *testing.T) {
+	src := "email,password,role\nalice@acme.com,secret,50\n\nbob@acme.com\ncarl@acme.com,,\n"
+
+	rows, err := usersImportParse(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("usersImportParse returned error: %v", err)
+	}
+
+	if len(rows) != 3 {
+		t.Fatalf("usersImportParse returned %d rows, want 3", len(rows))
+	}
+
+	if r := rows[0]; r.Email != "alice@acme.com" || r.Password != "secret" || !r.HasRole || r.Role != 50 || r.Line != 2 {
+		t.Fatalf("unexpected first row %+v", r)
+	}
+
+	if r := rows[1]; r.Email != "bob@acme.com" || r.Password != "" || r.HasRole {
+		t.Fatalf("unexpected second row %+v", r)
+	}
+
+	if r := rows[2]; r.Email != "carl@acme.com" || r.HasRole {
+		t.Fatalf("unexpected third row %+v", r)
+	}
+}
+
+func TestUsersImportParseExport(t *testing.T) {
+	users := []backend.User{
+		{ID: "u1", AccountID: "a1", Email: "alice@acme.com", Role: 100},
+		{ID: "u2", AccountID: "a1", Email: "bob@acme.com", Role: 0},
+	}
+
+	var buf bytes.Buffer
+	if err := usersExportCSV(&buf, users); err != nil {
+		t.Fatalf("usersExportCSV returned error: %v", err)
+	}
+
+	rows, err := usersImportParse(&buf)
+	if err != nil {
+		t.Fatalf("usersImportParse returned error: %v", err)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf("usersImportParse returned %d rows, want 2", len(rows))
+	}
+	if r := rows[0]; r.Email != "alice@acme.com" || r.Password != "" || !r.HasRole || r.Role != 100 || r.Line != 2 {
+		t.Fatalf("unexpected first row %+v", r)
+	}
+	if r := rows[1]; r.Email != "bob@acme.com" || !r.HasRole || r.Role != 0 {
+		t.Fatalf("unexpected second row %+v", r)
+	}
+}
+
+func TestUsersImportParseHeaderOrder(t *testing.T) {
+	rows, err := usersImportParse(strings.NewReader("Role,Email,Password\n50,alice@acme.com,secret\n"))
+	if err != nil {
+		t.Fatalf("usersImportParse returned error: %v", err)
+	}
+
+	if len(rows) != 1 || rows[0].Email != "alice@acme.com" || rows[0].Password != "secret" || rows[0].Role != 50 {
+		t.Fatalf("usersImportParse = %+v, want the columns matched by name", rows)
+	}
+}
+
+func TestUsersImportGeneratesPasswords(t *testing.T) {
+	rows, err := usersImportParse(strings.NewReader("alice@acme.com,secret\nbob@acme.com,,50\n"))
+	if err != nil {
+		t.Fatalf("usersImportParse returned error: %v", err)
+	}
+
+	if !usersImportGeneratesPasswords(rows) {
+		t.Fatal("usersImportGeneratesPasswords = false with a row without password")
+	}
+	if usersImportGeneratesPasswords(rows[:1]) {
+		t.Fatal("usersImportGeneratesPasswords = true when every row has a password")
+	}
+}
+
+func TestUsersImportParseRejectsInvalidRole(t *testing.T) {
+	if _, err := usersImportParse(strings.NewReader("alice@acme.com,pw,admin\n")); err == nil {
+		t.Fatal("usersImportParse returned nil error for invalid role")
+	}
+}
+
+func TestUsersImportRunReportsErrors(t *testing.T) {
+	rows := []*usersImportRow{{Email: "a"}, {Email: "b"}, {Email: "c"}}
+
+	var calls int32
+	results := usersImportRun(rows, 2, 0, func(row *usersImportRow) error {
+		atomic.AddInt32(&calls, 1)
+		if row.Email == "b" {
+			return errors.New("boom")
+		}
+		row.Status = usersImportCreated
+		return nil
+	})
+
+	if calls != 3 {
+		t.Fatalf("usersImportRun called fn %d times, want 3", calls)
+	}
+
+	if results[1].Status != usersImportFailed || results[1].Err != "boom" {
+		t.Fatalf("unexpected failed row %+v", results[1])
+	}
+	if results[0].Status != usersImportCreated || results[2].Status != usersImportCreated {
+		t.Fatalf("unexpected rows %+v %+v", results[0], results[2])
+	}
+}
+
+func TestUsersImportWriteReportMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.csv")
+	rows := []*usersImportRow{{Line: 1, Email: "a@acme.com", Password: "pw", Generated: true, Status: usersImportCreated}}
+
+	if err := usersImportWriteReport(path, rows); err != nil {
+		t.Fatalf("usersImportWriteReport returned error: %v", err)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := fi.Mode().Perm(); mode != 0600 {
+		t.Fatalf("report mode = %v, want 0600", mode)
+	}
+}
+
+func TestRandomPassword(t *testing.T) {
+	pw, err := randomPassword(16)
+	if err != nil {
+		t.Fatalf("randomPassword returned error: %v", err)
+	}
+	if len(pw) != 16 {
+		t.Fatalf("randomPassword returned %q, want 16 characters", pw)
+	}
+}
+
+func TestUsersExportCSV(t *testing.T) {
+	users := []backend.User{{
+		ID:        "u1",
+		AccountID: "a1",
+		Email:     "alice@acme.com",
+		Role:      100,
+		Created:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}}
+
+	var buf bytes.Buffer
+	if err := usersExportCSV(&buf, users); err != nil {
+		t.Fatalf("usersExportCSV returned error: %v", err)
+	}
+
+	want := "id,accountId,email,role,created\nu1,a1,alice@acme.com,100,2024-01-02T03:04:05Z\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("usersExportCSV returned %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/staticbackendhq/backend-go"
+)
+
+// usersResetPasswordCmd resets the password of a user
+var usersResetPasswordCmd = &cobra.Command{
+	Use:   "reset-password <email>",
+	Short: "Reset the password of a user.",
+	Long: fmt.Sprintf(`
+%s
+
+Generates a password reset code for the user and uses it to set a new
+password. You'll be prompted for the new password unless %s is provided.
+
+Use %s to only print the reset code, for instance to test your own reset
+password flow.
+	`,
+		clbold("Reset a user's password"),
+		clbold("--password"),
+		clbold("--code-only"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !setBackend() {
+			return
+		}
+
+		if len(args) == 0 {
+			printError("Argument missing: email — please supply an email.")
+			return
+		}
+
+		email := args[0]
+
+		tok, ok := getRootToken()
+		if !ok {
+			return
+		}
+
+		code, err := backend.GetPasswordResetCode(tok, email)
+		if err != nil {
+			printError("unable to get a reset code: %v", err)
+			return
+		}
+
+		if codeOnly, _ := cmd.Flags().GetBool("code-only"); codeOnly {
+			fmt.Println(code)
+			return
+		}
+
+		password, _ := cmd.Flags().GetString("password")
+		if len(password) == 0 {
+			password, err = readPassword(bufio.NewReader(os.Stdin), "enter the new password: ")
+			if err != nil {
+				fmt.Println("error: ", err)
+				return
+			}
+			password = cleanConfigValue(password)
+		}
+
+		if len(password) == 0 {
+			printError("the new password cannot be empty")
+			return
+		}
+
+		if err := backend.ResetPassword(email, code, password); err != nil {
+			printError("An error occurred: %v", err)
+			return
+		}
+
+		printSuccess("the password of %s has been reset", email)
+	},
+}
+
+func init() {
+	usersCmd.AddCommand(usersResetPasswordCmd)
+
+	usersResetPasswordCmd.Flags().String("password", "", "new password, prompted when omitted")
+	usersResetPasswordCmd.Flags().Bool("code-only", false, "only print the password reset code")
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/staticbackendhq/backend-go"
+)
+
+// usersSetRoleCmd changes the role of a user
+var usersSetRoleCmd = &cobra.Command{
+	Use:   "set-role <email> <role>",
+	Short: "Change the role of a user.",
+	Long: fmt.Sprintf(`
+%s
+
+Sets the numeric role of the user with the given email. Roles are between
+0 and 100, users with a role of 100 are account owners.
+
+$> backend users set-role bob@acme.com 50
+	`,
+		clbold("Change a user's role"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !setBackend() {
+			return
+		}
+
+		if len(args) < 2 {
+			printError("Arguments missing: please supply an email and role.")
+			return
+		}
+
+		email := args[0]
+		role, err := strconv.Atoi(args[1])
+		if err != nil || role < 0 || role > 100 {
+			printError("invalid role %q: must be a number between 0 and 100", args[1])
+			return
+		}
+
+		tok, ok := getRootToken()
+		if !ok {
+			return
+		}
+
+		accountID, ok := usersAccountID(cmd)
+		if !ok {
+			return
+		}
+
+		if err := backend.SetRole(tok, accountID, email, role); err != nil {
+			printError("An error occurred: %v", err)
+			return
+		}
+
+		printSuccess("the role of %s is now %d", email, role)
+	},
+}
+
+func init() {
+	usersCmd.AddCommand(usersSetRoleCmd)
+	addUsersAccountIDFlag(usersSetRoleCmd)
+}
@@ -0,0 +1,215 @@
+// Package devserver runs a StaticBackend development server inside the
+// current process, for Go integration tests.
+//
+//	func TestSignup(t *testing.T) {
+//		srv := devserver.New(t)
+//
+//		backend.PublicKey = srv.PublicKey
+//		backend.Region = srv.URL
+//		...
+//	}
+//
+// It uses the memory data store and the same credentials as the
+// "backend server" command of the CLI.
+//
+// StaticBackend keeps process-wide state and cannot be stopped, so a single
+// server is started by the first Start call and shared by every Server of
+// the test binary. Each Server has its own URL that stops accepting
+// requests once closed, but the data is shared: tests running in parallel
+// should use their own repositories or users.
+package devserver
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	staticbackend "github.com/staticbackendhq/core"
+	sbconfig "github.com/staticbackendhq/core/config"
+	"github.com/staticbackendhq/core/logger"
+)
+
+// Credentials of the dev server.
+const (
+	PublicKey     = "dev_memory_pk"
+	RootToken     = "safe-to-use-in-dev-root-token"
+	AdminEmail    = "admin@dev.com"
+	AdminPassword = "devpw1234"
+	AppSecret     = "staticbackend-cli-dev-secret-32b"
+)
+
+// startTimeout is how long Start waits for the server to accept requests.
+const startTimeout = 30 * time.Second
+
+// Server is a handle to the in-process dev server.
+type Server struct {
+	// URL is the base URL of the server, use it as backend.Region.
+	URL       string
+	PublicKey string
+	RootToken string
+	// AdminEmail and AdminPassword sign in the account owner.
+	AdminEmail    string
+	AdminPassword string
+
+	srv       *http.Server
+	closeOnce sync.Once
+}
+
+var (
+	coreOnce sync.Once
+	coreURL  *url.URL
+	coreErr  error
+)
+
+// AppConfig returns the StaticBackend configuration of a dev server
+// listening on port with the memory data store.
+func AppConfig(port string) sbconfig.AppConfig {
+	return sbconfig.AppConfig{
+		AppEnv:          "dev",
+		AppSecret:       AppSecret,
+		FromCLI:         "yes",
+		Port:            port,
+		DatabaseURL:     "mem",
+		DataStore:       "mem",
+		RedisHost:       "mem",
+		LocalStorageURL: "http://localhost:" + port,
+		ActivateFlag:    "no-stripe-test-flag",
+	}
+}
+
+// TB is the part of testing.TB used by New. The package does not import
+// testing since the CLI uses its credentials and configuration.
+type TB interface {
+	Helper()
+	Cleanup(func())
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+}
+
+// New starts a server for t and closes it when the test completes, t fails
+// immediately if the server does not start.
+func New(t TB) *Server {
+	t.Helper()
+
+	s, err := Start()
+	if err != nil {
+		t.Fatalf("devserver: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := s.Close(); err != nil {
+			t.Errorf("devserver: %v", err)
+		}
+	})
+
+	return s
+}
+
+// Start returns a Server once the dev server accepts requests and the admin
+// account exists. Close must be called when done.
+func Start() (*Server, error) {
+	coreOnce.Do(func() {
+		coreURL, coreErr = startCore()
+	})
+	if coreErr != nil {
+		return nil, coreErr
+	}
+
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		URL:           "http://" + l.Addr().String(),
+		PublicKey:     PublicKey,
+		RootToken:     RootToken,
+		AdminEmail:    AdminEmail,
+		AdminPassword: AdminPassword,
+		srv:           &http.Server{Handler: httputil.NewSingleHostReverseProxy(coreURL)},
+	}
+
+	go s.srv.Serve(l)
+
+	return s, nil
+}
+
+// Close stops accepting requests on the server URL, closing a Server more
+// than once is a no-op. The data is kept for the next Servers of the
+// process.
+func (s *Server) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		err = s.srv.Close()
+	})
+	return err
+}
+
+func startCore() (*url.URL, error) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return nil, err
+	}
+	port := strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+	l.Close()
+
+	c := AppConfig(port)
+	c.LogConsoleLevel = "error"
+
+	go staticbackend.Start(c, logger.Get(c))
+
+	base, err := url.Parse("http://localhost:" + port)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := waitReady(base.String(), startTimeout); err != nil {
+		return nil, err
+	}
+
+	if err := initAdmin(base.String()); err != nil {
+		return nil, fmt.Errorf("unable to create the %s account: %w", AdminEmail, err)
+	}
+
+	return base, nil
+}
+
+// waitReady polls base with backoff until the server answers.
+func waitReady(base string, timeout time.Duration) error {
+	client := http.Client{Timeout: 2 * time.Second}
+	deadline := time.Now().Add(timeout)
+
+	for wait := 50 * time.Millisecond; ; wait *= 2 {
+		resp, err := client.Get(base + "/")
+		if err == nil {
+			resp.Body.Close()
+			return nil
+		}
+
+		if wait > time.Second {
+			wait = time.Second
+		}
+		if time.Now().Add(wait).After(deadline) {
+			return fmt.Errorf("server not ready after %v: %w", timeout, err)
+		}
+		time.Sleep(wait)
+	}
+}
+
+func initAdmin(base string) error {
+	resp, err := http.Get(base + "/account/init?email=" + url.QueryEscape(AdminEmail) + "&mem=1")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("account init returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package devserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testing.TB must keep satisfying TB so tests can pass their t to New.
+var _ TB = testing.TB(nil)
+
+func TestAppConfig(t *testing.T) {
+	c := AppConfig("9123")
+
+	if c.Port != "9123" || c.LocalStorageURL != "http://localhost:9123" {
+		t.Fatalf("AppConfig port = %q, storage URL = %q", c.Port, c.LocalStorageURL)
+	}
+	if c.DataStore != "mem" || len(c.AppSecret) != 32 {
+		t.Fatalf("AppConfig data store = %q, secret length = %d", c.DataStore, len(c.AppSecret))
+	}
+}
+
+func TestInitAdmin(t *testing.T) {
+	var email string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/account/init" {
+			http.NotFound(w, r)
+			return
+		}
+		email = r.URL.Query().Get("email")
+	}))
+	defer ts.Close()
+
+	if err := waitReady(ts.URL, time.Second); err != nil {
+		t.Fatalf("waitReady returned error: %v", err)
+	}
+
+	if err := initAdmin(ts.URL); err != nil {
+		t.Fatalf("initAdmin returned error: %v", err)
+	}
+	if email != AdminEmail {
+		t.Fatalf("initAdmin email = %q, want %q", email, AdminEmail)
+	}
+}
+
+func TestWaitReadyTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	url := ts.URL
+	ts.Close()
+
+	if err := waitReady(url, 200*time.Millisecond); err == nil {
+		t.Fatal("waitReady returned nil error for a closed server")
+	}
+}
@@ -3,12 +3,14 @@ module github.com/staticbackendhq/cli
 go 1.26.4
 
 require (
+	github.com/dop251/goja v0.0.0-20260311135729-065cd970411c
 	github.com/gookit/color v1.2.2
 	github.com/spf13/cobra v1.10.2
 	github.com/spf13/viper v1.7.0
 	github.com/staticbackendhq/backend-go v1.7.0
 	github.com/staticbackendhq/core v1.5.0
 	golang.org/x/term v0.40.0
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 require (
@@ -51,7 +53,6 @@ require (
 	github.com/blevesearch/zapx/v17 v17.1.2 // indirect
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/dlclark/regexp2 v1.11.4 // indirect
-	github.com/dop251/goja v0.0.0-20260311135729-065cd970411c // indirect
 	github.com/dustin/go-humanize v1.0.1 // indirect
 	github.com/fsnotify/fsnotify v1.4.9 // indirect
 	github.com/gbrlsnchs/jwt/v3 v3.0.0-rc.1 // indirect
@@ -114,7 +115,6 @@ require (
 	google.golang.org/protobuf v1.36.6 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
 	gopkg.in/natefinch/lumberjack.v2 v2.0.0 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 	modernc.org/libc v1.67.6 // indirect
 	modernc.org/mathutil v1.7.1 // indirect
 	modernc.org/memory v1.11.0 // indirect